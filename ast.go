@@ -1,8 +1,9 @@
 package main
 
 type AssignExpr struct {
-	name  *Token
-	value Expr
+	name     *Token
+	operator *Token
+	value    Expr
 }
 
 type BinaryExpr struct {
@@ -15,11 +16,13 @@ type CallExpr struct {
 	callee    Expr
 	paren     *Token
 	arguments []Expr
+	names     []*Token
 }
 
 type GetExpr struct {
-	object Expr
-	name   *Token
+	object   Expr
+	name     *Token
+	optional bool
 }
 
 type GroupingExpr struct {
@@ -37,9 +40,10 @@ type LogicalExpr struct {
 }
 
 type SetExpr struct {
-	object Expr
-	name   *Token
-	value  Expr
+	object   Expr
+	name     *Token
+	operator *Token
+	value    Expr
 }
 
 type SuperExpr struct {
@@ -61,20 +65,121 @@ type VariableExpr struct {
 }
 
 type FunctionExpr struct {
-	name   *Token
-	paren  *Token
-	params []*Token
-	body   []Stmt
+	name      *Token
+	paren     *Token
+	params    []*Token
+	defaults  []Expr
+	rest      *Token
+	body      []Stmt
+	doc       string
+	generator bool
+}
+
+type ListExpr struct {
+	bracket  *Token
+	elements []Expr
+}
+
+type MapExpr struct {
+	brace  *Token
+	keys   []Expr
+	values []Expr
+}
+
+type IndexExpr struct {
+	object  Expr
+	bracket *Token
+	index   Expr
+}
+
+type SetIndexExpr struct {
+	object   Expr
+	bracket  *Token
+	index    Expr
+	operator *Token
+	value    Expr
+}
+
+type UpdateExpr struct {
+	target   Expr
+	operator *Token
+	prefix   bool
+}
+
+type ConditionalExpr struct {
+	condition  Expr
+	question   *Token
+	thenBranch Expr
+	elseBranch Expr
+}
+
+type RangeExpr struct {
+	start    Expr
+	operator *Token
+	end      Expr
+	step     Expr
+}
+
+type SliceExpr struct {
+	object  Expr
+	bracket *Token
+	start   Expr
+	end     Expr
+	step    Expr
+}
+
+type SpreadExpr struct {
+	ellipsis   *Token
+	expression Expr
+}
+
+type DestructureExpr struct {
+	pattern Expr
+	equals  *Token
+	value   Expr
+}
+
+type InterpolationExpr struct {
+	parts []Expr
 }
 
 type BlockStmt struct {
 	statements []Stmt
 }
 
+type TraitStmt struct {
+	name    *Token
+	methods []*FunctionExpr
+	doc     string
+}
+
+type InterfaceStmt struct {
+	name    *Token
+	methods []*FunctionExpr
+	doc     string
+}
+
+type EnumStmt struct {
+	name    *Token
+	members []*Token
+	params  [][]*Token
+	docs    []string
+	doc     string
+}
+
 type ClassStmt struct {
-	name       *Token
-	superclass *VariableExpr
-	methods    []*FunctionExpr
+	name            *Token
+	superclass      *VariableExpr
+	traits          []*VariableExpr
+	interfaces      []*VariableExpr
+	methods         []*FunctionExpr
+	abstractMethods []*FunctionExpr
+	staticMethods   []*FunctionExpr
+	getters         []*FunctionExpr
+	setters         []*FunctionExpr
+	fields          []*VarStmt
+	staticFields    []*VarStmt
+	doc             string
 }
 
 type ExpressionStmt struct {
@@ -94,6 +199,14 @@ type PrintStmt struct {
 type VarStmt struct {
 	name        *Token
 	initializer Expr
+	doc         string
+	constant    bool
+}
+
+type DestructureStmt struct {
+	keyword     *Token
+	pattern     Expr
+	initializer Expr
 }
 
 type WhileStmt struct {
@@ -108,11 +221,23 @@ type ForStmt struct {
 	body        Stmt
 }
 
+type ForInStmt struct {
+	name     *Token
+	keyword  *Token
+	iterable Expr
+	body     Stmt
+}
+
 type ReturnStmt struct {
 	keyword *Token
 	value   Expr
 }
 
+type YieldStmt struct {
+	keyword *Token
+	value   Expr
+}
+
 type ContinueStmt struct {
 	keyword *Token
 }
@@ -126,20 +251,29 @@ type IncludeStmt struct {
 	path    *Token
 }
 
-func MakeAssignExpr(name *Token, value Expr) *AssignExpr {
-	return &AssignExpr{name: name, value: value}
+type MatchStmt struct {
+	keyword  *Token
+	subject  Expr
+	cases    []*Token
+	patterns [][]Expr
+	guards   []Expr
+	bodies   []Stmt
+}
+
+func MakeAssignExpr(name *Token, operator *Token, value Expr) *AssignExpr {
+	return &AssignExpr{name: name, operator: operator, value: value}
 }
 
 func MakeBinaryExpr(left Expr, operator *Token, right Expr) *BinaryExpr {
 	return &BinaryExpr{left: left, operator: operator, right: right}
 }
 
-func MakeCallExpr(callee Expr, paren *Token, arguments []Expr) *CallExpr {
-	return &CallExpr{callee: callee, paren: paren, arguments: arguments}
+func MakeCallExpr(callee Expr, paren *Token, arguments []Expr, names []*Token) *CallExpr {
+	return &CallExpr{callee: callee, paren: paren, arguments: arguments, names: names}
 }
 
-func MakeGetExpr(object Expr, name *Token) *GetExpr {
-	return &GetExpr{object: object, name: name}
+func MakeGetExpr(object Expr, name *Token, optional bool) *GetExpr {
+	return &GetExpr{object: object, name: name, optional: optional}
 }
 
 func MakeGroupingExpr(expression Expr) *GroupingExpr {
@@ -154,8 +288,8 @@ func MakeLogicalExpr(left Expr, operator *Token, right Expr) *LogicalExpr {
 	return &LogicalExpr{left: left, operator: operator, right: right}
 }
 
-func MakeSetExpr(object Expr, name *Token, value Expr) *SetExpr {
-	return &SetExpr{object: object, name: name, value: value}
+func MakeSetExpr(object Expr, name *Token, operator *Token, value Expr) *SetExpr {
+	return &SetExpr{object: object, name: name, operator: operator, value: value}
 }
 
 func MakeSuperExpr(keyword *Token, method *Token) *SuperExpr {
@@ -174,16 +308,72 @@ func MakeVariableExpr(name *Token) *VariableExpr {
 	return &VariableExpr{name: name}
 }
 
-func MakeFunctionExpr(name *Token, paren *Token, params []*Token, body []Stmt) *FunctionExpr {
-	return &FunctionExpr{name: name, paren: paren, params: params, body: body}
+func MakeFunctionExpr(name *Token, paren *Token, params []*Token, defaults []Expr, rest *Token, body []Stmt, doc string, generator bool) *FunctionExpr {
+	return &FunctionExpr{name: name, paren: paren, params: params, defaults: defaults, rest: rest, body: body, doc: doc, generator: generator}
+}
+
+func MakeListExpr(bracket *Token, elements []Expr) *ListExpr {
+	return &ListExpr{bracket: bracket, elements: elements}
+}
+
+func MakeMapExpr(brace *Token, keys []Expr, values []Expr) *MapExpr {
+	return &MapExpr{brace: brace, keys: keys, values: values}
+}
+
+func MakeIndexExpr(object Expr, bracket *Token, index Expr) *IndexExpr {
+	return &IndexExpr{object: object, bracket: bracket, index: index}
+}
+
+func MakeSetIndexExpr(object Expr, bracket *Token, index Expr, operator *Token, value Expr) *SetIndexExpr {
+	return &SetIndexExpr{object: object, bracket: bracket, index: index, operator: operator, value: value}
+}
+
+func MakeUpdateExpr(target Expr, operator *Token, prefix bool) *UpdateExpr {
+	return &UpdateExpr{target: target, operator: operator, prefix: prefix}
+}
+
+func MakeConditionalExpr(condition Expr, question *Token, thenBranch Expr, elseBranch Expr) *ConditionalExpr {
+	return &ConditionalExpr{condition: condition, question: question, thenBranch: thenBranch, elseBranch: elseBranch}
+}
+
+func MakeRangeExpr(start Expr, operator *Token, end Expr, step Expr) *RangeExpr {
+	return &RangeExpr{start: start, operator: operator, end: end, step: step}
+}
+
+func MakeSliceExpr(object Expr, bracket *Token, start Expr, end Expr, step Expr) *SliceExpr {
+	return &SliceExpr{object: object, bracket: bracket, start: start, end: end, step: step}
+}
+
+func MakeSpreadExpr(ellipsis *Token, expression Expr) *SpreadExpr {
+	return &SpreadExpr{ellipsis: ellipsis, expression: expression}
+}
+
+func MakeDestructureExpr(pattern Expr, equals *Token, value Expr) *DestructureExpr {
+	return &DestructureExpr{pattern: pattern, equals: equals, value: value}
+}
+
+func MakeInterpolationExpr(parts []Expr) *InterpolationExpr {
+	return &InterpolationExpr{parts: parts}
 }
 
 func MakeBlockStmt(statements []Stmt) *BlockStmt {
 	return &BlockStmt{statements: statements}
 }
 
-func MakeClassStmt(name *Token, superclass *VariableExpr, methods []*FunctionExpr) *ClassStmt {
-	return &ClassStmt{name: name, superclass: superclass, methods: methods}
+func MakeTraitStmt(name *Token, methods []*FunctionExpr, doc string) *TraitStmt {
+	return &TraitStmt{name: name, methods: methods, doc: doc}
+}
+
+func MakeInterfaceStmt(name *Token, methods []*FunctionExpr, doc string) *InterfaceStmt {
+	return &InterfaceStmt{name: name, methods: methods, doc: doc}
+}
+
+func MakeEnumStmt(name *Token, members []*Token, params [][]*Token, docs []string, doc string) *EnumStmt {
+	return &EnumStmt{name: name, members: members, params: params, docs: docs, doc: doc}
+}
+
+func MakeClassStmt(name *Token, superclass *VariableExpr, traits []*VariableExpr, interfaces []*VariableExpr, methods []*FunctionExpr, abstractMethods []*FunctionExpr, staticMethods []*FunctionExpr, getters []*FunctionExpr, setters []*FunctionExpr, fields []*VarStmt, staticFields []*VarStmt, doc string) *ClassStmt {
+	return &ClassStmt{name: name, superclass: superclass, traits: traits, interfaces: interfaces, methods: methods, abstractMethods: abstractMethods, staticMethods: staticMethods, getters: getters, setters: setters, fields: fields, staticFields: staticFields, doc: doc}
 }
 
 func MakeExpressionStmt(expression Expr) *ExpressionStmt {
@@ -198,8 +388,12 @@ func MakePrintStmt(expression Expr) *PrintStmt {
 	return &PrintStmt{expression: expression}
 }
 
-func MakeVarStmt(name *Token, initializer Expr) *VarStmt {
-	return &VarStmt{name: name, initializer: initializer}
+func MakeVarStmt(name *Token, initializer Expr, doc string, constant bool) *VarStmt {
+	return &VarStmt{name: name, initializer: initializer, doc: doc, constant: constant}
+}
+
+func MakeDestructureStmt(keyword *Token, pattern Expr, initializer Expr) *DestructureStmt {
+	return &DestructureStmt{keyword: keyword, pattern: pattern, initializer: initializer}
 }
 
 func MakeWhileStmt(condition Expr, body Stmt) *WhileStmt {
@@ -210,10 +404,18 @@ func MakeForStmt(initializer Stmt, condition Expr, increment Expr, body Stmt) *F
 	return &ForStmt{initializer: initializer, condition: condition, increment: increment, body: body}
 }
 
+func MakeForInStmt(name *Token, keyword *Token, iterable Expr, body Stmt) *ForInStmt {
+	return &ForInStmt{name: name, keyword: keyword, iterable: iterable, body: body}
+}
+
 func MakeReturnStmt(keyword *Token, value Expr) *ReturnStmt {
 	return &ReturnStmt{keyword: keyword, value: value}
 }
 
+func MakeYieldStmt(keyword *Token, value Expr) *YieldStmt {
+	return &YieldStmt{keyword: keyword, value: value}
+}
+
 func MakeContinueStmt(keyword *Token) *ContinueStmt {
 	return &ContinueStmt{keyword: keyword}
 }
@@ -226,6 +428,10 @@ func MakeIncludeStmt(keyword *Token, path *Token) *IncludeStmt {
 	return &IncludeStmt{keyword: keyword, path: path}
 }
 
+func MakeMatchStmt(keyword *Token, subject Expr, cases []*Token, patterns [][]Expr, guards []Expr, bodies []Stmt) *MatchStmt {
+	return &MatchStmt{keyword: keyword, subject: subject, cases: cases, patterns: patterns, guards: guards, bodies: bodies}
+}
+
 func (expr *AssignExpr) accept(v ExprVisitor) Any {
 	return v.visitAssignExpr(expr)
 }
@@ -278,10 +484,66 @@ func (expr *FunctionExpr) accept(v ExprVisitor) Any {
 	return v.visitFunctionExpr(expr)
 }
 
+func (expr *ListExpr) accept(v ExprVisitor) Any {
+	return v.visitListExpr(expr)
+}
+
+func (expr *MapExpr) accept(v ExprVisitor) Any {
+	return v.visitMapExpr(expr)
+}
+
+func (expr *IndexExpr) accept(v ExprVisitor) Any {
+	return v.visitIndexExpr(expr)
+}
+
+func (expr *SetIndexExpr) accept(v ExprVisitor) Any {
+	return v.visitSetIndexExpr(expr)
+}
+
+func (expr *UpdateExpr) accept(v ExprVisitor) Any {
+	return v.visitUpdateExpr(expr)
+}
+
+func (expr *ConditionalExpr) accept(v ExprVisitor) Any {
+	return v.visitConditionalExpr(expr)
+}
+
+func (expr *RangeExpr) accept(v ExprVisitor) Any {
+	return v.visitRangeExpr(expr)
+}
+
+func (expr *SliceExpr) accept(v ExprVisitor) Any {
+	return v.visitSliceExpr(expr)
+}
+
+func (expr *SpreadExpr) accept(v ExprVisitor) Any {
+	return v.visitSpreadExpr(expr)
+}
+
+func (expr *DestructureExpr) accept(v ExprVisitor) Any {
+	return v.visitDestructureExpr(expr)
+}
+
+func (expr *InterpolationExpr) accept(v ExprVisitor) Any {
+	return v.visitInterpolationExpr(expr)
+}
+
 func (expr *BlockStmt) accept(v StmtVisitor) Any {
 	return v.visitBlockStmt(expr)
 }
 
+func (expr *TraitStmt) accept(v StmtVisitor) Any {
+	return v.visitTraitStmt(expr)
+}
+
+func (expr *InterfaceStmt) accept(v StmtVisitor) Any {
+	return v.visitInterfaceStmt(expr)
+}
+
+func (expr *EnumStmt) accept(v StmtVisitor) Any {
+	return v.visitEnumStmt(expr)
+}
+
 func (expr *ClassStmt) accept(v StmtVisitor) Any {
 	return v.visitClassStmt(expr)
 }
@@ -302,6 +564,10 @@ func (expr *VarStmt) accept(v StmtVisitor) Any {
 	return v.visitVarStmt(expr)
 }
 
+func (expr *DestructureStmt) accept(v StmtVisitor) Any {
+	return v.visitDestructureStmt(expr)
+}
+
 func (expr *WhileStmt) accept(v StmtVisitor) Any {
 	return v.visitWhileStmt(expr)
 }
@@ -310,10 +576,18 @@ func (expr *ForStmt) accept(v StmtVisitor) Any {
 	return v.visitForStmt(expr)
 }
 
+func (expr *ForInStmt) accept(v StmtVisitor) Any {
+	return v.visitForInStmt(expr)
+}
+
 func (expr *ReturnStmt) accept(v StmtVisitor) Any {
 	return v.visitReturnStmt(expr)
 }
 
+func (expr *YieldStmt) accept(v StmtVisitor) Any {
+	return v.visitYieldStmt(expr)
+}
+
 func (expr *ContinueStmt) accept(v StmtVisitor) Any {
 	return v.visitContinueStmt(expr)
 }
@@ -325,3 +599,7 @@ func (expr *BreakStmt) accept(v StmtVisitor) Any {
 func (expr *IncludeStmt) accept(v StmtVisitor) Any {
 	return v.visitIncludeStmt(expr)
 }
+
+func (expr *MatchStmt) accept(v StmtVisitor) Any {
+	return v.visitMatchStmt(expr)
+}
@@ -0,0 +1,375 @@
+package main
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+)
+
+type LoxList struct {
+	elements []Any
+	frozen   bool
+}
+
+func MakeLoxList(elements []Any) *LoxList {
+	return &LoxList{elements: elements}
+}
+
+func (l *LoxList) String() string {
+	return MakeValuePrinter(nil).stringify(l)
+}
+
+type LoxMapEntry struct {
+	key   Any
+	value Any
+}
+
+// LoxMap keeps entries in insertion order.
+type LoxMap struct {
+	entries map[Any]*LoxMapEntry
+	order   []*LoxMapEntry
+	frozen  bool
+}
+
+func MakeLoxMap() *LoxMap {
+	return &LoxMap{
+		entries: make(map[Any]*LoxMapEntry),
+		order:   make([]*LoxMapEntry, 0),
+	}
+}
+
+type ratKey string
+
+// mapKey returns value used for addressing map entries, so that numbers
+// which are equal address the same entry regardless of representation.
+// Integers in the range of int64 are keys themselves, other numbers are
+// keyed by their exact ratio. Values of enum members are keyed by their
+// associated values.
+func mapKey(key Any) Any {
+	switch value := key.(type) {
+	case float:
+		if value == math.Trunc(value) && math.Abs(value) < math.MaxInt64 {
+			return int64(value)
+		}
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			return value
+		}
+		return ratioKey(toRat(value))
+	case *big.Int:
+		return ratioKey(new(big.Rat).SetInt(value))
+	case *Decimal:
+		return ratioKey(value.Rat())
+	case *LoxEnumMember:
+		return value.key()
+	}
+	return key
+}
+
+func ratioKey(value *big.Rat) Any {
+	if value.IsInt() && value.Num().IsInt64() {
+		return value.Num().Int64()
+	}
+	return ratKey(value.RatString())
+}
+
+func (m *LoxMap) get(key Any) (Any, bool) {
+	if entry, ok := m.entries[mapKey(key)]; ok {
+		return entry.value, true
+	}
+	return nil, false
+}
+
+func (m *LoxMap) set(key Any, value Any) {
+	normalized := mapKey(key)
+	if entry, ok := m.entries[normalized]; ok {
+		entry.value = value
+		return
+	}
+
+	entry := &LoxMapEntry{key: key, value: value}
+	m.entries[normalized] = entry
+	m.order = append(m.order, entry)
+}
+
+func (m *LoxMap) remove(key Any) (Any, bool) {
+	normalized := mapKey(key)
+	entry, ok := m.entries[normalized]
+	if !ok {
+		return nil, false
+	}
+
+	delete(m.entries, normalized)
+	for index, item := range m.order {
+		if item == entry {
+			m.order = append(m.order[:index], m.order[index+1:]...)
+			break
+		}
+	}
+	return entry.value, true
+}
+
+func (m *LoxMap) keys() []Any {
+	keys := make([]Any, len(m.order))
+	for index, entry := range m.order {
+		keys[index] = entry.key
+	}
+	return keys
+}
+
+func (m *LoxMap) values() []Any {
+	values := make([]Any, len(m.order))
+	for index, entry := range m.order {
+		values[index] = entry.value
+	}
+	return values
+}
+
+func (m *LoxMap) String() string {
+	return MakeValuePrinter(nil).stringify(m)
+}
+
+// repr returns text representation of value used inside of collections,
+// where strings are quoted.
+func repr(value Any) string {
+	if value, ok := value.(string); ok {
+		return strconv.Quote(value)
+	}
+	return stringify(value)
+}
+
+func (i *Interpreter) visitListExpr(expr *ListExpr) Any {
+	elements := make([]Any, 0, len(expr.elements))
+	for _, element := range expr.elements {
+		if spread, ok := element.(*SpreadExpr); ok {
+			elements = append(elements, i.spread(spread)...)
+		} else {
+			elements = append(elements, i.evaluate(element))
+		}
+	}
+	return MakeLoxList(elements)
+}
+
+func (i *Interpreter) visitMapExpr(expr *MapExpr) Any {
+	result := MakeLoxMap()
+	for index, key := range expr.keys {
+		if spread, ok := key.(*SpreadExpr); ok {
+			entries, ok := i.evaluate(spread.expression).(*LoxMap)
+			if !ok {
+				i.context.runtimeError(spread.ellipsis, "Only maps can be spread into map literals.")
+			}
+			for _, entry := range entries.order {
+				result.set(entry.key, entry.value)
+			}
+			continue
+		}
+		result.set(i.evaluate(key), i.evaluate(expr.values[index]))
+	}
+	return result
+}
+
+// spread returns values of iterable spread into a call or a list literal.
+func (i *Interpreter) spread(expr *SpreadExpr) []Any {
+	values := make([]Any, 0)
+	iterator := i.iterate(expr.ellipsis, i.evaluate(expr.expression))
+	for iterator.hasNext() {
+		values = append(values, iterator.next())
+	}
+	return values
+}
+
+func (i *Interpreter) visitSpreadExpr(expr *SpreadExpr) Any {
+	i.context.runtimeError(expr.ellipsis, "Spread is only allowed in calls and collection literals.")
+	return nil
+}
+
+func (i *Interpreter) visitIndexExpr(expr *IndexExpr) Any {
+	value, _ := i.chain(expr)
+	return value
+}
+
+func (i *Interpreter) visitSetIndexExpr(expr *SetIndexExpr) Any {
+	object := i.evaluate(expr.object)
+	index := i.evaluate(expr.index)
+
+	var value Any
+	if expr.operator != nil {
+		current := i.getIndex(expr.bracket, object, index)
+		value = i.binary(expr.operator, current, i.evaluate(expr.value))
+	} else {
+		value = i.evaluate(expr.value)
+	}
+
+	i.setIndex(expr.bracket, object, index, value)
+	return value
+}
+
+func (i *Interpreter) getIndex(bracket *Token, object Any, index Any) Any {
+	switch object := object.(type) {
+	case *LoxList:
+		return object.elements[i.checkIndex(bracket, index, len(object.elements))]
+	case *LoxMap:
+		value, _ := object.get(index)
+		return value
+	case string:
+		runes := []rune(object)
+		return string(runes[i.checkIndex(bracket, index, len(runes))])
+	case *LoxInstance:
+		if method := findSpecial(object, "__index__"); method != nil {
+			return i.callSpecial(bracket, method, index)
+		}
+		i.context.runtimeError(bracket, "'%s' instance can't be indexed, it has no '__index__' method.", object.klass.name)
+	}
+
+	i.context.runtimeError(bracket, "Only lists, maps and strings can be indexed.")
+	return nil
+}
+
+func (i *Interpreter) setIndex(bracket *Token, object Any, index Any, value Any) {
+	switch object := object.(type) {
+	case *LoxList:
+		if object.frozen {
+			i.context.runtimeError(bracket, "Can't modify frozen list.")
+		}
+		object.elements[i.checkIndex(bracket, index, len(object.elements))] = value
+		return
+	case *LoxMap:
+		if object.frozen {
+			i.context.runtimeError(bracket, "Can't modify frozen map.")
+		}
+		object.set(index, value)
+		return
+	case string:
+		i.context.runtimeError(bracket, "Strings are immutable.")
+	case *LoxInstance:
+		if method := findSpecial(object, "__setindex__"); method != nil {
+			i.callSpecial(bracket, method, index, value)
+			return
+		}
+		i.context.runtimeError(bracket, "'%s' instance doesn't support index assignment, it has no '__setindex__' method.", object.klass.name)
+	}
+
+	i.context.runtimeError(bracket, "Only lists and maps support index assignment.")
+}
+
+func (i *Interpreter) visitSliceExpr(expr *SliceExpr) Any {
+	value, _ := i.chain(expr)
+	return value
+}
+
+func (i *Interpreter) slice(expr *SliceExpr, object Any) Any {
+	var start, end, step Any = nil, nil, nil
+	if expr.start != nil {
+		start = i.evaluate(expr.start)
+	}
+	if expr.end != nil {
+		end = i.evaluate(expr.end)
+	}
+	if expr.step != nil {
+		step = i.evaluate(expr.step)
+	}
+
+	switch object := object.(type) {
+	case *LoxList:
+		indices := i.sliceIndices(expr.bracket, start, end, step, len(object.elements))
+		elements := make([]Any, len(indices))
+		for index, position := range indices {
+			elements[index] = object.elements[position]
+		}
+		return MakeLoxList(elements)
+	case string:
+		runes := []rune(object)
+		indices := i.sliceIndices(expr.bracket, start, end, step, len(runes))
+		result := make([]rune, len(indices))
+		for index, position := range indices {
+			result[index] = runes[position]
+		}
+		return string(result)
+	}
+
+	i.context.runtimeError(expr.bracket, "Only lists and strings can be sliced.")
+	return nil
+}
+
+// sliceIndices returns positions selected by slice of sequence with given
+// length. Omitted bounds default to the whole sequence in the direction of
+// step, negative bounds count from the end and out of range bounds are
+// clamped.
+func (i *Interpreter) sliceIndices(bracket *Token, start Any, end Any, step Any, length int) []int {
+	stride := i.sliceBound(bracket, step, 1)
+	if stride == 0 {
+		i.context.runtimeError(bracket, "Slice step can't be zero.")
+	}
+
+	// Bounds are clamped to [lower, upper], -1 stands for the position
+	// before the first element when slicing backwards.
+	lower, upper := int64(0), int64(length)
+	if stride < 0 {
+		lower, upper = -1, int64(length)-1
+	}
+
+	clamp := func(value Any, fallback int64) int64 {
+		if value == nil {
+			return fallback
+		}
+		bound := i.sliceBound(bracket, value, 0)
+		if bound < 0 {
+			bound += int64(length)
+		}
+		if bound < lower {
+			return lower
+		}
+		if bound > upper {
+			return upper
+		}
+		return bound
+	}
+
+	indices := make([]int, 0)
+	if stride > 0 {
+		from, to := clamp(start, lower), clamp(end, upper)
+		for position := from; position < to; position += stride {
+			indices = append(indices, int(position))
+			if stride >= to-position {
+				break
+			}
+		}
+	} else {
+		from, to := clamp(start, upper), clamp(end, lower)
+		for position := from; position > to; position += stride {
+			indices = append(indices, int(position))
+			if stride <= to-position {
+				break
+			}
+		}
+	}
+	return indices
+}
+
+func (i *Interpreter) sliceBound(bracket *Token, value Any, fallback int64) int64 {
+	if value == nil {
+		return fallback
+	}
+	if value, ok := value.(int64); ok {
+		return value
+	}
+	i.context.runtimeError(bracket, "Slice bounds and step must be integers.")
+	return 0
+}
+
+// checkIndex validates index into sequence of given length, negative
+// indices count from the end of sequence.
+func (i *Interpreter) checkIndex(bracket *Token, index Any, length int) int {
+	value, ok := index.(int64)
+	if !ok {
+		i.context.runtimeError(bracket, "Index must be an integer.")
+	}
+
+	if value < 0 {
+		value += int64(length)
+	}
+	if value < 0 || value >= int64(length) {
+		i.context.runtimeError(bracket, "Index %v is out of bounds for length %v.", index, length)
+	}
+
+	return int(value)
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"math/big"
+	"strings"
+)
+
+// decimalPrecision is number of fractional digits kept when division
+// result can't be represented exactly.
+const decimalPrecision = 34
+
+var bigTen = big.NewInt(10)
+
+// Decimal is an exact decimal number equal to unscaled * 10^-scale.
+type Decimal struct {
+	unscaled *big.Int
+	scale    int
+}
+
+func MakeDecimal(unscaled *big.Int, scale int) *Decimal {
+	return &Decimal{unscaled: unscaled, scale: scale}
+}
+
+// ParseDecimal parses decimal number such as "-12.340".
+func ParseDecimal(text string) (*Decimal, bool) {
+	scale := 0
+	if index := strings.IndexByte(text, '.'); index >= 0 {
+		scale = len(text) - index - 1
+		text = text[:index] + text[index+1:]
+	}
+
+	unscaled, ok := new(big.Int).SetString(text, 10)
+	if !ok {
+		return nil, false
+	}
+
+	return MakeDecimal(unscaled, scale), true
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(bigTen, big.NewInt(int64(n)), nil)
+}
+
+// rescale returns unscaled value of d represented with given larger scale.
+func (d *Decimal) rescale(scale int) *big.Int {
+	if scale == d.scale {
+		return d.unscaled
+	}
+	return new(big.Int).Mul(d.unscaled, pow10(scale-d.scale))
+}
+
+func alignDecimals(a *Decimal, b *Decimal) (*big.Int, *big.Int, int) {
+	scale := a.scale
+	if b.scale > scale {
+		scale = b.scale
+	}
+	return a.rescale(scale), b.rescale(scale), scale
+}
+
+func (d *Decimal) Add(other *Decimal) *Decimal {
+	a, b, scale := alignDecimals(d, other)
+	return MakeDecimal(new(big.Int).Add(a, b), scale)
+}
+
+func (d *Decimal) Sub(other *Decimal) *Decimal {
+	a, b, scale := alignDecimals(d, other)
+	return MakeDecimal(new(big.Int).Sub(a, b), scale)
+}
+
+func (d *Decimal) Mul(other *Decimal) *Decimal {
+	return MakeDecimal(new(big.Int).Mul(d.unscaled, other.unscaled), d.scale+other.scale)
+}
+
+func (d *Decimal) Neg() *Decimal {
+	return MakeDecimal(new(big.Int).Neg(d.unscaled), d.scale)
+}
+
+// Quo divides exactly when the quotient has finite decimal representation,
+// otherwise the result is rounded half to even to decimalPrecision digits.
+func (d *Decimal) Quo(other *Decimal) *Decimal {
+	quotient := new(big.Rat).Quo(d.Rat(), other.Rat())
+
+	scale := d.scale
+	if other.scale > scale {
+		scale = other.scale
+	}
+	for scale < decimalPrecision {
+		scaled := new(big.Rat).Mul(quotient, new(big.Rat).SetInt(pow10(scale)))
+		if scaled.IsInt() {
+			return MakeDecimal(scaled.Num(), scale)
+		}
+		scale++
+	}
+
+	scaled := new(big.Rat).Mul(quotient, new(big.Rat).SetInt(pow10(decimalPrecision)))
+	return MakeDecimal(roundHalfEven(scaled), decimalPrecision)
+}
+
+func roundHalfEven(value *big.Rat) *big.Int {
+	quotient, remainder := new(big.Int).QuoRem(value.Num(), value.Denom(), new(big.Int))
+
+	twice := new(big.Int).Mul(new(big.Int).Abs(remainder), big.NewInt(2))
+	switch twice.Cmp(value.Denom()) {
+	case 1:
+		quotient.Add(quotient, big.NewInt(int64(value.Sign())))
+	case 0:
+		if quotient.Bit(0) == 1 {
+			quotient.Add(quotient, big.NewInt(int64(value.Sign())))
+		}
+	}
+	return quotient
+}
+
+// FloorDiv returns quotient of floored division as an integral decimal.
+func (d *Decimal) FloorDiv(other *Decimal) *Decimal {
+	a, b, _ := alignDecimals(d, other)
+	return MakeDecimal(floorDivBig(a, b), 0)
+}
+
+// Mod returns remainder of floored division.
+func (d *Decimal) Mod(other *Decimal) *Decimal {
+	a, b, scale := alignDecimals(d, other)
+	return MakeDecimal(floorModBig(a, b), scale)
+}
+
+func (d *Decimal) Cmp(other *Decimal) int {
+	a, b, _ := alignDecimals(d, other)
+	return a.Cmp(b)
+}
+
+func (d *Decimal) Sign() int {
+	return d.unscaled.Sign()
+}
+
+func (d *Decimal) IsInt() bool {
+	return d.Rat().IsInt()
+}
+
+func (d *Decimal) Rat() *big.Rat {
+	return new(big.Rat).SetFrac(d.unscaled, pow10(d.scale))
+}
+
+// Int returns integral part of d, truncated towards zero.
+func (d *Decimal) Int() *big.Int {
+	return new(big.Int).Quo(d.unscaled, pow10(d.scale))
+}
+
+func (d *Decimal) String() string {
+	digits := new(big.Int).Abs(d.unscaled).String()
+	if d.scale > 0 {
+		if len(digits) <= d.scale {
+			digits = strings.Repeat("0", d.scale-len(digits)+1) + digits
+		}
+		digits = digits[:len(digits)-d.scale] + "." + digits[len(digits)-d.scale:]
+	}
+
+	if d.unscaled.Sign() < 0 {
+		return "-" + digits
+	}
+	return digits
+}
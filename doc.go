@@ -0,0 +1,507 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type DocFunction struct {
+	Name   string
+	Params []string
+	Doc    string
+	Static bool
+
+	// Abstract is set for abstract methods and methods of interfaces.
+	Abstract bool
+
+	// Accessor is "get" or "set" for accessors, empty for other functions.
+	Accessor string
+}
+
+func (f *DocFunction) Signature() string {
+	signature := fmt.Sprintf("%s(%s)", f.Name, strings.Join(f.Params, ", "))
+	if f.Accessor != "" {
+		signature = f.Accessor + " " + signature
+	}
+	if f.Static {
+		return "static " + signature
+	}
+	if f.Abstract {
+		return "abstract " + signature
+	}
+	return signature
+}
+
+type DocField struct {
+	Name   string
+	Doc    string
+	Static bool
+}
+
+func (f *DocField) Signature() string {
+	if f.Static {
+		return "static " + f.Name
+	}
+	return f.Name
+}
+
+type DocClass struct {
+	// Kind is "class", "trait", "interface" or "enum".
+	Kind       string
+	Name       string
+	Superclass string
+	Traits     []string
+	Interfaces []string
+	Doc        string
+	Fields     []*DocField
+	Methods    []*DocFunction
+}
+
+type DocModule struct {
+	Name      string
+	Functions []*DocFunction
+	Classes   []*DocClass
+
+	page     string
+	includes []*DocModule
+}
+
+// Page returns file name (without extension) of the module documentation.
+func (m *DocModule) Page() string {
+	return m.page
+}
+
+// docClassKey identifies class by the module declaring it, as classes of
+// different modules can share their names.
+type docClassKey struct {
+	module *DocModule
+	name   string
+}
+
+type DocGenerator struct {
+	context        *LoxContext
+	sourceResolver SourceResolver
+	modules        []*DocModule
+	visited        map[string]*DocModule
+	classes        map[docClassKey]bool
+
+	// pages are file names taken by modules, including the index page.
+	pages map[string]bool
+}
+
+func MakeDocGenerator(context *LoxContext, sourceResolver SourceResolver) *DocGenerator {
+	return &DocGenerator{
+		context:        context,
+		sourceResolver: sourceResolver,
+		modules:        make([]*DocModule, 0),
+		visited:        make(map[string]*DocModule),
+		classes:        make(map[docClassKey]bool),
+		pages:          map[string]bool{"index": true},
+	}
+}
+
+// pageName returns unique file name for documentation of module. Characters
+// which aren't safe in file names or links are replaced by "_", and a number
+// is appended to names that are already taken.
+func (g *DocGenerator) pageName(module string) string {
+	var out strings.Builder
+	for _, c := range []byte(strings.TrimSuffix(module, filepath.Ext(module))) {
+		if c < ' ' || c == 0x7f || strings.IndexByte(`/\:*?"<>|#% `, c) >= 0 {
+			out.WriteByte('_')
+		} else {
+			out.WriteByte(c)
+		}
+	}
+
+	name := out.String()
+	for count := 2; g.pages[name]; count++ {
+		name = fmt.Sprintf("%s-%d", out.String(), count)
+	}
+	g.pages[name] = true
+	return name
+}
+
+// collect parses given file and every file included from it, and gathers
+// documentation of their top-level declarations.
+func (g *DocGenerator) collect(name string) (*DocModule, error) {
+	if module, ok := g.visited[name]; ok {
+		return module, nil
+	}
+	module := &DocModule{Name: name, page: g.pageName(name)}
+	g.visited[name] = module
+
+	source, err := g.sourceResolver.Resolve(g.context, name)
+	if err != nil {
+		return nil, err
+	}
+	if g.context.hadError {
+		return nil, fmt.Errorf("failed to parse '%s'", name)
+	}
+
+	g.modules = append(g.modules, module)
+
+	for _, statement := range source.Body {
+		switch stmt := statement.(type) {
+		case *IncludeStmt:
+			included, err := g.collect(stmt.path.literal.(string))
+			if err != nil {
+				return nil, err
+			}
+			module.includes = append(module.includes, included)
+			break
+
+		case *ExpressionStmt:
+			if function, ok := stmt.expression.(*FunctionExpr); ok && function.name != nil {
+				module.Functions = append(module.Functions, makeDocFunction(function))
+			}
+			break
+
+		case *ClassStmt:
+			class := &DocClass{Kind: "class", Name: stmt.name.lexme, Doc: stmt.doc}
+			if stmt.superclass != nil {
+				class.Superclass = stmt.superclass.name.lexme
+			}
+			for _, trait := range stmt.traits {
+				class.Traits = append(class.Traits, trait.name.lexme)
+			}
+			for _, iface := range stmt.interfaces {
+				class.Interfaces = append(class.Interfaces, iface.name.lexme)
+			}
+			for _, field := range stmt.staticFields {
+				class.Fields = append(class.Fields, &DocField{Name: field.name.lexme, Doc: field.doc, Static: true})
+			}
+			for _, field := range stmt.fields {
+				if !isPrivate(field.name) {
+					class.Fields = append(class.Fields, &DocField{Name: field.name.lexme, Doc: field.doc})
+				}
+			}
+			for _, method := range stmt.staticMethods {
+				if method.name != nil {
+					function := makeDocFunction(method)
+					function.Static = true
+					class.Methods = append(class.Methods, function)
+				}
+			}
+			for _, getter := range stmt.getters {
+				if !isPrivate(getter.name) {
+					function := makeDocFunction(getter)
+					function.Accessor = "get"
+					class.Methods = append(class.Methods, function)
+				}
+			}
+			for _, setter := range stmt.setters {
+				if !isPrivate(setter.name) {
+					function := makeDocFunction(setter)
+					function.Accessor = "set"
+					class.Methods = append(class.Methods, function)
+				}
+			}
+			for _, method := range stmt.methods {
+				// Private methods aren't part of the documented API.
+				if method.name != nil && !isPrivate(method.name) {
+					class.Methods = append(class.Methods, makeDocFunction(method))
+				}
+			}
+			for _, method := range stmt.abstractMethods {
+				function := makeDocFunction(method)
+				function.Abstract = true
+				class.Methods = append(class.Methods, function)
+			}
+			module.Classes = append(module.Classes, class)
+			g.classes[docClassKey{module, class.Name}] = true
+			break
+
+		case *TraitStmt:
+			trait := &DocClass{Kind: "trait", Name: stmt.name.lexme, Doc: stmt.doc}
+			for _, method := range stmt.methods {
+				if method.name != nil {
+					trait.Methods = append(trait.Methods, makeDocFunction(method))
+				}
+			}
+			module.Classes = append(module.Classes, trait)
+			g.classes[docClassKey{module, trait.Name}] = true
+			break
+
+		case *EnumStmt:
+			enum := &DocClass{Kind: "enum", Name: stmt.name.lexme, Doc: stmt.doc}
+			for index, member := range stmt.members {
+				name := member.lexme
+				if params := stmt.params[index]; len(params) > 0 {
+					values := make([]string, len(params))
+					for position, param := range params {
+						values[position] = param.lexme
+					}
+					name += "(" + strings.Join(values, ", ") + ")"
+				}
+				enum.Fields = append(enum.Fields, &DocField{Name: name, Doc: stmt.docs[index]})
+			}
+			module.Classes = append(module.Classes, enum)
+			g.classes[docClassKey{module, enum.Name}] = true
+			break
+
+		case *InterfaceStmt:
+			iface := &DocClass{Kind: "interface", Name: stmt.name.lexme, Doc: stmt.doc}
+			for _, method := range stmt.methods {
+				iface.Methods = append(iface.Methods, makeDocFunction(method))
+			}
+			module.Classes = append(module.Classes, iface)
+			g.classes[docClassKey{module, iface.Name}] = true
+			break
+		}
+	}
+
+	return module, nil
+}
+
+func makeDocFunction(function *FunctionExpr) *DocFunction {
+	params := make([]string, 0, len(function.params)+1)
+	for index, param := range function.params {
+		if value := function.defaults[index]; value != nil {
+			params = append(params, param.lexme+" = "+docDefault(value))
+		} else {
+			params = append(params, param.lexme)
+		}
+	}
+	if function.rest != nil {
+		params = append(params, "..."+function.rest.lexme)
+	}
+
+	return &DocFunction{Name: function.name.lexme, Params: params, Doc: function.doc}
+}
+
+// docDefault returns text shown for default parameter value, only literal
+// values are spelled out.
+func docDefault(value Expr) string {
+	if literal, ok := value.(*LiteralExpr); ok {
+		return repr(literal.value)
+	}
+	return "…"
+}
+
+// findClass returns module declaring class referred to from given module,
+// looking first into the module itself and then into modules it includes.
+func (g *DocGenerator) findClass(module *DocModule, name string, visited map[*DocModule]bool) *DocModule {
+	if visited[module] {
+		return nil
+	}
+	visited[module] = true
+
+	if g.classes[docClassKey{module, name}] {
+		return module
+	}
+	for _, included := range module.includes {
+		if found := g.findClass(included, name, visited); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// classLink returns relative link to the documentation of class referred to
+// from given module, or empty string when the class is not declared in
+// documented files.
+func (g *DocGenerator) classLink(module *DocModule, name string, ext string) string {
+	found := g.findClass(module, name, make(map[*DocModule]bool))
+	if found == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s.%s#class-%s", found.Page(), ext, name)
+}
+
+// markdownLink returns name of class or trait, linked to its documentation
+// when it's documented.
+func (g *DocGenerator) markdownLink(module *DocModule, name string) string {
+	if link := g.classLink(module, name, "md"); link != "" {
+		return fmt.Sprintf("[%s](%s)", name, link)
+	}
+	return name
+}
+
+func (g *DocGenerator) markdownLinks(module *DocModule, names []string) string {
+	links := make([]string, len(names))
+	for index, name := range names {
+		links[index] = g.markdownLink(module, name)
+	}
+	return strings.Join(links, ", ")
+}
+
+func (g *DocGenerator) renderMarkdown(module *DocModule) string {
+	var out strings.Builder
+
+	fmt.Fprintf(&out, "# %s\n\n", module.Name)
+
+	if len(module.Classes) > 0 {
+		out.WriteString("## Classes\n\n")
+	}
+	for _, class := range module.Classes {
+		fmt.Fprintf(&out, "<a id=\"class-%s\"></a>\n\n### %s %s", class.Name, class.Kind, class.Name)
+		if class.Superclass != "" {
+			fmt.Fprintf(&out, " < %s", g.markdownLink(module, class.Superclass))
+		}
+		if len(class.Traits) > 0 {
+			fmt.Fprintf(&out, " with %s", g.markdownLinks(module, class.Traits))
+		}
+		if len(class.Interfaces) > 0 {
+			fmt.Fprintf(&out, " implements %s", g.markdownLinks(module, class.Interfaces))
+		}
+		out.WriteString("\n\n")
+		writeMarkdownDoc(&out, class.Doc)
+
+		for _, field := range class.Fields {
+			fmt.Fprintf(&out, "#### `%s`\n\n", field.Signature())
+			writeMarkdownDoc(&out, field.Doc)
+		}
+		for _, method := range class.Methods {
+			fmt.Fprintf(&out, "#### `%s`\n\n", method.Signature())
+			writeMarkdownDoc(&out, method.Doc)
+		}
+	}
+
+	if len(module.Functions) > 0 {
+		out.WriteString("## Functions\n\n")
+	}
+	for _, function := range module.Functions {
+		fmt.Fprintf(&out, "### `fun %s`\n\n", function.Signature())
+		writeMarkdownDoc(&out, function.Doc)
+	}
+
+	return out.String()
+}
+
+func writeMarkdownDoc(out *strings.Builder, doc string) {
+	if doc != "" {
+		out.WriteString(doc)
+		out.WriteString("\n\n")
+	}
+}
+
+func (g *DocGenerator) renderMarkdownIndex() string {
+	var out strings.Builder
+
+	out.WriteString("# API documentation\n\n")
+	for _, module := range g.modules {
+		fmt.Fprintf(&out, "- [%s](%s.md)\n", module.Name, module.Page())
+	}
+
+	return out.String()
+}
+
+var docHtmlTemplate = template.Must(template.New("module").Funcs(template.FuncMap{
+	"doc": func(doc string) template.HTML {
+		if doc == "" {
+			return ""
+		}
+		return template.HTML("<p class=\"doc\">" + template.HTMLEscapeString(doc) + "</p>")
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; max-width: 48em; margin: 2em auto; }
+code { background: #f4f4f4; padding: 0 .2em; }
+.doc { white-space: pre-line; }
+</style>
+</head>
+<body>
+{{if .Modules}}<h1>API documentation</h1>
+<ul>
+{{range .Modules}}<li><a href="{{.Page}}.html">{{.Name}}</a></li>
+{{end}}</ul>
+{{else}}<p><a href="index.html">Index</a></p>
+<h1>{{.Module.Name}}</h1>
+{{if .Module.Classes}}<h2>Classes</h2>
+{{range .Classes}}<h3 id="class-{{.Name}}">{{.Kind}} {{.Name}}{{if .Superclass}} &lt; {{if .SuperclassLink}}<a href="{{.SuperclassLink}}">{{.Superclass}}</a>{{else}}{{.Superclass}}{{end}}{{end}}{{if .Traits}} with {{template "links" .TraitLinks}}{{end}}{{if .Interfaces}} implements {{template "links" .InterfaceLinks}}{{end}}</h3>
+{{doc .Doc}}
+{{range .Fields}}<h4><code>{{.Signature}}</code></h4>
+{{doc .Doc}}
+{{end}}{{range .Methods}}<h4><code>{{.Signature}}</code></h4>
+{{doc .Doc}}
+{{end}}{{end}}{{end}}{{if .Module.Functions}}<h2>Functions</h2>
+{{range .Module.Functions}}<h3><code>fun {{.Signature}}</code></h3>
+{{doc .Doc}}
+{{end}}{{end}}{{end}}</body>
+</html>
+{{define "links"}}{{range $index, $link := .}}{{if $index}}, {{end}}{{if $link.Link}}<a href="{{$link.Link}}">{{$link.Name}}</a>{{else}}{{$link.Name}}{{end}}{{end}}{{end}}`))
+
+type docHtmlClass struct {
+	*DocClass
+	SuperclassLink string
+	TraitLinks     []docHtmlLink
+	InterfaceLinks []docHtmlLink
+}
+
+type docHtmlLink struct {
+	Name string
+	Link string
+}
+
+type docHtmlPage struct {
+	Title   string
+	Modules []*DocModule
+	Module  *DocModule
+	Classes []docHtmlClass
+}
+
+func (g *DocGenerator) htmlLinks(module *DocModule, names []string) []docHtmlLink {
+	links := make([]docHtmlLink, len(names))
+	for index, name := range names {
+		links[index] = docHtmlLink{name, g.classLink(module, name, "html")}
+	}
+	return links
+}
+
+func (g *DocGenerator) renderHtml(module *DocModule) (string, error) {
+	page := docHtmlPage{Title: module.Name, Module: module}
+	for _, class := range module.Classes {
+		page.Classes = append(page.Classes, docHtmlClass{class, g.classLink(module, class.Superclass, "html"), g.htmlLinks(module, class.Traits), g.htmlLinks(module, class.Interfaces)})
+	}
+
+	var out strings.Builder
+	err := docHtmlTemplate.Execute(&out, page)
+	return out.String(), err
+}
+
+func (g *DocGenerator) renderHtmlIndex() (string, error) {
+	var out strings.Builder
+	err := docHtmlTemplate.Execute(&out, docHtmlPage{Title: "API documentation", Modules: g.modules})
+	return out.String(), err
+}
+
+// write renders Markdown and HTML pages of collected modules into directory.
+func (g *DocGenerator) write(directory string) error {
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		return err
+	}
+
+	pages := map[string]string{"index.md": g.renderMarkdownIndex()}
+
+	index, err := g.renderHtmlIndex()
+	if err != nil {
+		return err
+	}
+	pages["index.html"] = index
+
+	for _, module := range g.modules {
+		pages[module.Page()+".md"] = g.renderMarkdown(module)
+
+		html, err := g.renderHtml(module)
+		if err != nil {
+			return err
+		}
+		pages[module.Page()+".html"] = html
+	}
+
+	for name, content := range pages {
+		if err := ioutil.WriteFile(filepath.Join(directory, name), []byte(content), 0644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// generateDocs writes documentation of given modules in test/doc into
+// temporary directory and returns function reading the generated pages.
+func generateDocs(t *testing.T, names ...string) func(name string) string {
+	generator := MakeDocGenerator(MakeContext(), MakeFileSourceResolver(filepath.Join("test", "doc")))
+	for _, name := range names {
+		if _, err := generator.collect(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	directory := t.TempDir()
+	if err := generator.write(directory); err != nil {
+		t.Fatal(err)
+	}
+
+	return func(name string) string {
+		contents, err := ioutil.ReadFile(filepath.Join(directory, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(contents)
+	}
+}
+
+func checkPage(t *testing.T, name string, page string, expected ...string) {
+	for _, text := range expected {
+		if !strings.Contains(page, text) {
+			t.Errorf("%s doesn't contain %q:\n%s", name, text, page)
+		}
+	}
+}
+
+func TestDocPages(t *testing.T) {
+	read := generateDocs(t, "shapes.lox")
+
+	checkPage(t, "index.md", read("index.md"), "[shapes.lox](shapes.md)", "[base.lox](base.md)")
+	checkPage(t, "index.html", read("index.html"), `<a href="shapes.html">shapes.lox</a>`)
+	checkPage(t, "shapes.md", read("shapes.md"),
+		"### class Circle < [Base](base.md#class-Base)\n\nA circle.",
+		"#### `init(radius)`\n\nCreates circle of given radius.",
+		"### `fun area(shape)`\n\nReturns area of shape.")
+	checkPage(t, "shapes.html", read("shapes.html"), `<a href="base.html#class-Base">Base</a>`)
+	checkPage(t, "base.md", read("base.md"), "Base of shapes.")
+
+	if page := read("shapes.md"); strings.Contains(page, "#secret") {
+		t.Errorf("shapes.md documents private method:\n%s", page)
+	}
+}
+
+func TestDocPageNames(t *testing.T) {
+	read := generateDocs(t, "index.lox", "a_b.lox", "a/b.lox")
+
+	// Module named index doesn't replace the index page, and modules whose
+	// page names would be the same get their own pages.
+	checkPage(t, "index.md", read("index.md"), "[index.lox](index-2.md)", "[a.b.lox](a.b.md)", "[a_b.lox](a_b.md)", "[a/b.lox](a_b-2.md)")
+	checkPage(t, "a.b.md", read("a.b.md"), "Base of a.b.")
+	checkPage(t, "a_b.md", read("a_b.md"), "Base of a_b.")
+	checkPage(t, "a_b-2.md", read("a_b-2.md"), "Base of a/b.")
+
+	// Classes link to the class of the same name declared in their module,
+	// or in the modules it includes.
+	checkPage(t, "index-2.md", read("index-2.md"), "class Shape < [Base](a.b.md#class-Base)")
+	checkPage(t, "a_b.md", read("a_b.md"), "class Circle < [Base](a_b.md#class-Base)")
+	checkPage(t, "index-2.html", read("index-2.html"), `<a href="a.b.html#class-Base">Base</a>`)
+	checkPage(t, "a_b.html", read("a_b.html"), `<a href="a_b.html#class-Base">Base</a>`)
+}
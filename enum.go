@@ -0,0 +1,304 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LoxEnum is a namespace of enum members, iterating over it yields the
+// members in order of their declaration.
+type LoxEnum struct {
+	name    string
+	members []*LoxEnumMember
+}
+
+func (e *LoxEnum) String() string {
+	return fmt.Sprintf("enum %s", e.name)
+}
+
+func (e *LoxEnum) get(interpreter *Interpreter, name *Token) Any {
+	for _, member := range e.members {
+		if member.name == name.lexme {
+			return member
+		}
+	}
+
+	interpreter.context.runtimeError(name, "Undefined member '%s' of enum '%s'.", name.lexme, e.name)
+	return nil
+}
+
+func (e *LoxEnum) iterator() LoxIterator {
+	values := make([]Any, len(e.members))
+	for index, member := range e.members {
+		values[index] = member
+	}
+	return &sliceIterator{values: values}
+}
+
+// LoxEnumMember is a member of enum. Members declaring associated values
+// are called to create values of the member, which share its name and
+// ordinal and hold the associated values.
+type LoxEnumMember struct {
+	enum    *LoxEnum
+	name    string
+	ordinal int
+	params  []*Token
+
+	// values are associated values, they're nil for declared members.
+	values []Any
+}
+
+func (m *LoxEnumMember) String() string {
+	if m.values == nil {
+		return m.enum.name + "." + m.name
+	}
+	return MakeValuePrinter(nil).stringify(m)
+}
+
+// get returns name and ordinal of member, or its associated value.
+func (m *LoxEnumMember) get(interpreter *Interpreter, name *Token) Any {
+	switch name.lexme {
+	case "name":
+		return m.name
+	case "ordinal":
+		return int64(m.ordinal)
+	}
+
+	if m.values != nil {
+		for index, param := range m.params {
+			if param.lexme == name.lexme {
+				return m.values[index]
+			}
+		}
+	}
+
+	interpreter.context.runtimeError(name, "Undefined property '%s'.", name.lexme)
+	return nil
+}
+
+// constructor returns native function creating values of member with
+// associated values, or nil for other members.
+func (m *LoxEnumMember) constructor() *LoxStaticCallable {
+	if m.values != nil || len(m.params) == 0 {
+		return nil
+	}
+
+	return MakeLoxCallable(len(m.params), func(interpreter *Interpreter, arguments []Any) Any {
+		return &LoxEnumMember{enum: m.enum, name: m.name, ordinal: m.ordinal, params: m.params, values: arguments}
+	})
+}
+
+// equals compares members, values of members are equal when their
+// associated values are.
+func (m *LoxEnumMember) equals(other *LoxEnumMember) bool {
+	if m.enum != other.enum || m.ordinal != other.ordinal || (m.values == nil) != (other.values == nil) {
+		return false
+	}
+	for index, value := range m.values {
+		if !isEqual(value, other.values[index]) {
+			return false
+		}
+	}
+	return true
+}
+
+// enumKey addresses map entries of member values, values holds map keys of
+// associated values chained by valuesKey.
+type enumKey struct {
+	enum    *LoxEnum
+	ordinal int
+	values  Any
+}
+
+type valuesKey struct {
+	value Any
+	rest  Any
+}
+
+// key returns map key of member, which is the same for equal values.
+func (m *LoxEnumMember) key() Any {
+	if m.values == nil {
+		return m
+	}
+
+	var values Any = nil
+	for index := len(m.values) - 1; index >= 0; index-- {
+		values = valuesKey{mapKey(m.values[index]), values}
+	}
+	return enumKey{m.enum, m.ordinal, values}
+}
+
+// isDeclarationOf reports whether value was created by calling the
+// member.
+func (m *LoxEnumMember) isDeclarationOf(value Any) bool {
+	member, ok := value.(*LoxEnumMember)
+	return ok && member.values != nil && member.enum.members[member.ordinal] == m
+}
+
+func (i *Interpreter) visitEnumStmt(stmt *EnumStmt) Any {
+	enum := &LoxEnum{name: stmt.name.lexme, members: make([]*LoxEnumMember, len(stmt.members))}
+	for index, name := range stmt.members {
+		enum.members[index] = &LoxEnumMember{enum: enum, name: name.lexme, ordinal: index, params: stmt.params[index]}
+	}
+
+	i.environment.declare(stmt.name, enum, false)
+	return nil
+}
+
+// ordinals returns ordinals of compared members, which must belong to the
+// same enum.
+func (i *Interpreter) ordinals(operator *Token, left *LoxEnumMember, right Any) (Any, Any) {
+	member, ok := right.(*LoxEnumMember)
+	if !ok || member.enum != left.enum {
+		i.context.runtimeError(operator, "Only members of the same enum can be compared.")
+	}
+	return int64(left.ordinal), int64(member.ordinal)
+}
+
+// matchMember matches values of enum member with associated values, which
+// are matched by positional sub-patterns.
+func (i *Interpreter) matchMember(pattern *CallExpr, member *LoxEnumMember, value Any, environment *Environment) bool {
+	if len(pattern.arguments) > len(member.params) {
+		i.context.runtimeError(pattern.paren, "Expected at most %v patterns for member '%s' but got %v.", len(member.params), member, len(pattern.arguments))
+	}
+
+	if !member.isDeclarationOf(value) {
+		return false
+	}
+
+	matched := value.(*LoxEnumMember)
+	for index, argument := range pattern.arguments {
+		if !i.matchPattern(argument, matched.values[index], environment) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *Resolver) visitEnumStmt(stmt *EnumStmt) Any {
+	r.declare(stmt.name)
+	r.define(stmt.name)
+	if r.scopes.IsEmpty() {
+		r.globalEnums[stmt.name.lexme] = stmt
+	} else {
+		r.enums[len(r.enums)-1][stmt.name.lexme] = stmt
+	}
+
+	declared := make(map[string]bool)
+	for index, member := range stmt.members {
+		r.checkVariableName(member)
+		if declared[member.lexme] {
+			r.context.tokenError(member, "Member '%s' is already declared in this enum.", member.lexme)
+		}
+		declared[member.lexme] = true
+
+		params := make(map[string]bool)
+		for _, param := range stmt.params[index] {
+			r.checkVariableName(param)
+			if param.lexme == "name" || param.lexme == "ordinal" {
+				r.context.tokenError(param, "Associated value can't be named '%s'.", param.lexme)
+			} else if params[param.lexme] {
+				r.context.tokenError(param, "Associated value '%s' is already declared.", param.lexme)
+			}
+			params[param.lexme] = true
+		}
+	}
+	return nil
+}
+
+// findEnum returns declaration of enum named by expression, or nil when it
+// doesn't name an enum.
+func (r *Resolver) findEnum(expr Expr) *EnumStmt {
+	variable, ok := expr.(*VariableExpr)
+	if !ok {
+		return nil
+	}
+
+	name := variable.name.lexme
+	for index := r.scopes.Size() - 1; index >= 0; index-- {
+		if _, ok := r.scopes.Get(index)[name]; ok {
+			return r.enums[index][name]
+		}
+	}
+	return r.globalEnums[name]
+}
+
+// enumMember returns enum and name of its member referred to by pattern,
+// such as "Color.Red" or "Shape.Circle(r)". It also reports whether the
+// pattern matches the member, or each of its values, regardless of their
+// associated values.
+func (r *Resolver) enumMember(pattern Expr) (*EnumStmt, string, bool) {
+	call, isCall := pattern.(*CallExpr)
+	if isCall {
+		pattern = call.callee
+	}
+
+	get, ok := pattern.(*GetExpr)
+	if !ok {
+		return nil, "", false
+	}
+	enum := r.findEnum(get.object)
+	if enum == nil {
+		return nil, "", false
+	}
+
+	// Call patterns only match members with associated values.
+	name := get.name.lexme
+	for index, member := range enum.members {
+		if member.lexme == name && isCall {
+			return enum, name, len(enum.params[index]) > 0 && bindsOnly(call.arguments)
+		}
+	}
+	return enum, name, true
+}
+
+// bindsOnly reports whether patterns match any value.
+func bindsOnly(patterns []Expr) bool {
+	for _, pattern := range patterns {
+		if _, ok := pattern.(*VariableExpr); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// checkExhaustive reports match statement with cases for members of enum,
+// which doesn't cover all of them and has no catch-all case. Only unguarded
+// cases count as covering members.
+func (r *Resolver) checkExhaustive(stmt *MatchStmt) {
+	enums := make([]*EnumStmt, 0)
+	covered := make(map[*EnumStmt]map[string]bool)
+
+	for index, alternatives := range stmt.patterns {
+		guarded := stmt.guards[index] != nil
+		for _, pattern := range alternatives {
+			if _, ok := pattern.(*VariableExpr); ok && !guarded {
+				return
+			}
+
+			enum, member, complete := r.enumMember(pattern)
+			if enum == nil {
+				continue
+			}
+			if covered[enum] == nil {
+				enums = append(enums, enum)
+				covered[enum] = make(map[string]bool)
+			}
+			if complete && !guarded {
+				covered[enum][member] = true
+			}
+		}
+	}
+
+	for _, enum := range enums {
+		missing := make([]string, 0)
+		for _, member := range enum.members {
+			if !covered[enum][member.lexme] {
+				missing = append(missing, "'"+member.lexme+"'")
+			}
+		}
+		if len(missing) > 0 {
+			r.context.tokenError(stmt.keyword, "Match on enum '%s' isn't exhaustive, missing %s.", enum.name.lexme, strings.Join(missing, ", "))
+		}
+	}
+}
@@ -4,6 +4,11 @@ type Environment struct {
 	context   *LoxContext
 	values    map[string]Any
 	enclosing *Environment
+
+	// constants holds names of variables which can't be assigned, it's
+	// only checked at runtime for globals, the resolver reports the rest.
+	// The map is created by the first constant declaration.
+	constants map[string]bool
 }
 
 func MakeEnvironment(context *LoxContext, enclosing *Environment) *Environment {
@@ -26,6 +31,21 @@ func (e *Environment) define(name string, value Any) {
 	e.values[name] = value
 }
 
+// declare defines variable, unless it would redefine a constant.
+func (e *Environment) declare(name *Token, value Any, constant bool) {
+	if e.constants[name.lexme] {
+		e.context.runtimeError(name, "Can't redefine constant '%s'.", name.lexme)
+	}
+
+	e.values[name.lexme] = value
+	if constant {
+		if e.constants == nil {
+			e.constants = make(map[string]bool)
+		}
+		e.constants[name.lexme] = true
+	}
+}
+
 func (e *Environment) get(name *Token) Any {
 	if val, ok := e.values[name.lexme]; ok {
 		return val
@@ -54,6 +74,9 @@ func (e *Environment) ancestor(distance int) *Environment {
 
 func (e *Environment) assign(name *Token, value Any) {
 	if _, ok := e.values[name.lexme]; ok {
+		if e.constants[name.lexme] {
+			e.context.runtimeError(name, "Can't assign to constant '%s'.", name.lexme)
+		}
 		e.values[name.lexme] = value
 		return
 	}
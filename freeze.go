@@ -0,0 +1,33 @@
+package main
+
+// LoxFreezable is implemented by values which can be frozen by freeze(),
+// after which their fields, elements or entries can't be modified.
+// Freezing is shallow, values held by frozen ones stay mutable.
+type LoxFreezable interface {
+	freeze()
+	isFrozen() bool
+}
+
+func (i *LoxInstance) freeze() {
+	i.frozen = true
+}
+
+func (i *LoxInstance) isFrozen() bool {
+	return i.frozen
+}
+
+func (l *LoxList) freeze() {
+	l.frozen = true
+}
+
+func (l *LoxList) isFrozen() bool {
+	return l.frozen
+}
+
+func (m *LoxMap) freeze() {
+	m.frozen = true
+}
+
+func (m *LoxMap) isFrozen() bool {
+	return m.frozen
+}
@@ -0,0 +1,240 @@
+package main
+
+import "runtime"
+
+// LoxObject is implemented by values which have properties, such as
+// instances, classes and generators.
+type LoxObject interface {
+	get(interpreter *Interpreter, name *Token) Any
+}
+
+type generatorSignal struct {
+	value    Any
+	finished bool
+
+	// panic holds value the generator body panicked with, e.g. a runtime
+	// error, which is re-raised by the caller.
+	panic Any
+}
+
+// generatorClosed is raised in the body of closed generator to unwind it.
+type generatorClosed struct{}
+
+// LoxGenerator is returned by calls of functions containing "yield". The
+// function body runs as a coroutine on its own goroutine, which is
+// suspended at each yield until the caller asks for the next value, so only
+// one of them runs at a time. Generators abandoned by for-in loops are
+// closed, which ends their goroutine.
+//
+// The goroutine only refers to the coroutine, so the generator itself can
+// be garbage collected while its body is suspended. Its finalizer hands the
+// coroutine over to the interpreter, which closes it.
+type LoxGenerator struct {
+	*coroutine
+}
+
+type coroutine struct {
+	interpreter *Interpreter
+	function    *LoxFunction
+	arguments   []Any
+
+	// environment holds environment of the body while it's suspended.
+	environment *Environment
+
+	// resume receives false when the generator is closed.
+	resume chan bool
+	yield  chan generatorSignal
+
+	started  bool
+	running  bool
+	finished bool
+	buffered bool
+	value    Any
+}
+
+func MakeLoxGenerator(interpreter *Interpreter, function *LoxFunction, arguments []Any) *LoxGenerator {
+	interpreter.closeAbandoned()
+
+	generator := &LoxGenerator{&coroutine{
+		interpreter: interpreter,
+		function:    function,
+		arguments:   arguments,
+		resume:      make(chan bool),
+		yield:       make(chan generatorSignal),
+	}}
+	runtime.SetFinalizer(generator, func(generator *LoxGenerator) {
+		interpreter.abandon(generator.coroutine)
+	})
+	return generator
+}
+
+func (g *LoxGenerator) String() string {
+	return "generator"
+}
+
+func (g *coroutine) run() {
+	defer func() {
+		signal := generatorSignal{finished: true}
+		if r := recover(); r != nil && r != (generatorClosed{}) {
+			signal.panic = r
+		}
+		g.yield <- signal
+	}()
+
+	<-g.resume
+	// The body starts in the closure of the function instead of the
+	// environment of the caller, which would keep the generator alive.
+	g.interpreter.environment = g.function.closure
+	g.function.execute(g.interpreter, g.arguments)
+}
+
+// advance resumes the body until it yields the next value or finishes,
+// token is used to report that the generator is already running.
+func (g *coroutine) advance(token *Token) {
+	if g.finished || g.buffered {
+		return
+	}
+	if g.running {
+		g.interpreter.context.runtimeError(token, "Generator is already running.")
+	}
+
+	if !g.started {
+		g.started = true
+		go g.run()
+	}
+	signal := g.transfer(true)
+
+	if signal.panic != nil {
+		g.finished = true
+		panic(signal.panic)
+	}
+	if signal.finished {
+		g.finished = true
+		return
+	}
+
+	g.buffered = true
+	g.value = signal.value
+}
+
+// transfer switches to the body, which continues when proceed is set or
+// unwinds otherwise, and waits until it yields or finishes.
+func (g *coroutine) transfer(proceed bool) generatorSignal {
+	i := g.interpreter
+	environment, generator := i.environment, i.generator
+	i.generator = g
+	g.running = true
+
+	g.resume <- proceed
+	signal := <-g.yield
+
+	g.running = false
+	i.environment, i.generator = environment, generator
+	return signal
+}
+
+// close finishes suspended generator, unwinding its body, so that its
+// goroutine ends. It must be called by the goroutine of the interpreter.
+func (g *coroutine) close() {
+	if g.finished || g.running {
+		return
+	}
+
+	g.finished = true
+	g.buffered = false
+	g.value = nil
+	if g.started {
+		g.transfer(false)
+	}
+}
+
+// suspend is called from the body to hand value to the caller, it returns
+// once the caller resumes the generator.
+func (g *coroutine) suspend(value Any) {
+	g.environment = g.interpreter.environment
+	g.yield <- generatorSignal{value: value}
+	if !<-g.resume {
+		panic(generatorClosed{})
+	}
+	g.interpreter.environment = g.environment
+}
+
+func (g *coroutine) hasNext(token *Token) bool {
+	g.advance(token)
+	return !g.finished
+}
+
+// next returns the next yielded value, or nil once the generator is
+// finished.
+func (g *coroutine) next(token *Token) Any {
+	g.advance(token)
+	if g.finished {
+		return nil
+	}
+
+	g.buffered = false
+	value := g.value
+	g.value = nil
+	return value
+}
+
+// generatorIterator iterates over generator, reporting errors at token.
+type generatorIterator struct {
+	generator *LoxGenerator
+	token     *Token
+}
+
+func (it *generatorIterator) hasNext() bool {
+	return it.generator.hasNext(it.token)
+}
+
+func (it *generatorIterator) next() Any {
+	return it.generator.next(it.token)
+}
+
+func (g *LoxGenerator) get(interpreter *Interpreter, name *Token) Any {
+	switch name.lexme {
+	case "next":
+		return MakeLoxCallable(0, func(interpreter *Interpreter, arguments []Any) Any {
+			return g.next(name)
+		})
+	case "hasNext":
+		return MakeLoxCallable(0, func(interpreter *Interpreter, arguments []Any) Any {
+			return g.hasNext(name)
+		})
+	}
+
+	interpreter.context.runtimeError(name, "Undefined property '%s'.", name.lexme)
+	return nil
+}
+
+func (i *Interpreter) visitYieldStmt(stmt *YieldStmt) Any {
+	var value Any = nil
+	if stmt.value != nil {
+		value = i.evaluate(stmt.value)
+	}
+
+	i.generator.suspend(value)
+	return nil
+}
+
+// abandon is called by finalizers of generators, it queues their coroutines
+// to be closed by the interpreter.
+func (i *Interpreter) abandon(coroutine *coroutine) {
+	i.abandonedLock.Lock()
+	defer i.abandonedLock.Unlock()
+	i.abandoned = append(i.abandoned, coroutine)
+}
+
+// closeAbandoned closes coroutines of generators which were garbage
+// collected.
+func (i *Interpreter) closeAbandoned() {
+	i.abandonedLock.Lock()
+	abandoned := i.abandoned
+	i.abandoned = nil
+	i.abandonedLock.Unlock()
+
+	for _, coroutine := range abandoned {
+		coroutine.close()
+	}
+}
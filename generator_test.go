@@ -0,0 +1,39 @@
+package main
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+// run interprets code with given interpreter, failing test on syntax errors.
+func run(t *testing.T, interpreter *Interpreter, code string) {
+	source := &Source{Name: "<test>", Code: code}
+	tokens := MakeScanner(interpreter.context, source).scanTokens()
+	statements, _ := MakeParser(interpreter.context, tokens).parse()
+	MakeResolver(interpreter.context, interpreter, MakeFileSourceResolver("")).resolve(statements)
+	if interpreter.context.hadError {
+		t.Fatalf("failed to parse %q", code)
+	}
+	interpreter.interpret(statements)
+}
+
+func TestAbandonedGeneratorsAreClosed(t *testing.T) {
+	interpreter := MakeInterpreter(MakeContext())
+	run(t, interpreter, "fun pair() { yield 1; yield 2; }")
+
+	before := runtime.NumGoroutine()
+	run(t, interpreter, "for (var i in 1..100) { var generator = pair(); generator.next(); }")
+
+	// Finalizers run after garbage collection, abandoned generators are
+	// closed when the next generator is created.
+	for attempt := 0; attempt < 100 && runtime.NumGoroutine() > before; attempt++ {
+		runtime.GC()
+		time.Sleep(time.Millisecond)
+		run(t, interpreter, "pair();")
+	}
+
+	if running := runtime.NumGoroutine() - before; running > 0 {
+		t.Errorf("%d abandoned generators are still running", running)
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LoxInterface is a named set of method signatures, classes implementing it
+// are checked to provide all of them when they're defined.
+type LoxInterface struct {
+	name    string
+	methods []*FunctionExpr
+}
+
+func (iface *LoxInterface) String() string {
+	return fmt.Sprintf("interface %s", iface.name)
+}
+
+func (i *Interpreter) visitInterfaceStmt(stmt *InterfaceStmt) Any {
+	i.environment.declare(stmt.name, &LoxInterface{name: stmt.name.lexme, methods: stmt.methods}, false)
+	return nil
+}
+
+// interfaces evaluates interfaces implemented by class.
+func (i *Interpreter) interfaces(stmt *ClassStmt) []*LoxInterface {
+	interfaces := make([]*LoxInterface, 0, len(stmt.interfaces))
+	for _, expr := range stmt.interfaces {
+		iface, ok := i.evaluate(expr).(*LoxInterface)
+		if !ok {
+			i.context.runtimeError(expr.name, "'%s' isn't an interface.", expr.name.lexme)
+		}
+		interfaces = append(interfaces, iface)
+	}
+	return interfaces
+}
+
+// checkInterfaces reports methods of interfaces missing in class. Methods
+// can be inherited, come from traits or be declared abstract, in which
+// case subclasses have to implement them.
+func (i *Interpreter) checkInterfaces(stmt *ClassStmt, klass *LoxClass) {
+	for index, iface := range klass.interfaces {
+		missing := make([]string, 0)
+		for _, method := range iface.methods {
+			name := method.name.lexme
+			if klass.findMethod(name) == nil && !klass.isAbstract(name) {
+				missing = append(missing, "'"+name+"'")
+			}
+		}
+
+		if len(missing) > 0 {
+			i.context.runtimeError(stmt.interfaces[index].name, "Class '%s' doesn't implement %s of interface '%s'.", klass.name, strings.Join(missing, ", "), iface.name)
+		}
+	}
+}
+
+// isAbstract reports whether method name is declared abstract by the class
+// or its superclasses.
+func (c *LoxClass) isAbstract(name string) bool {
+	for klass := c; klass != nil; klass = klass.superclass {
+		for _, method := range klass.abstractMethods {
+			if method.name.lexme == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// unimplemented returns names of abstract methods, which aren't implemented
+// by the class or a superclass below the one declaring them.
+func (c *LoxClass) unimplemented() []string {
+	seen := make(map[string]bool)
+	missing := make([]string, 0)
+	for klass := c; klass != nil; klass = klass.superclass {
+		for _, method := range klass.abstractMethods {
+			if name := method.name.lexme; !seen[name] {
+				seen[name] = true
+				missing = append(missing, "'"+name+"'")
+			}
+		}
+		for name := range klass.methods {
+			seen[name] = true
+		}
+	}
+	return missing
+}
+
+// implements reports whether the class, or any of its superclasses,
+// implements interface.
+func (c *LoxClass) implements(iface *LoxInterface) bool {
+	for klass := c; klass != nil; klass = klass.superclass {
+		for _, implemented := range klass.interfaces {
+			if implemented == iface {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (r *Resolver) visitInterfaceStmt(stmt *InterfaceStmt) Any {
+	r.declare(stmt.name)
+	r.define(stmt.name)
+
+	declared := make(map[string]bool)
+	for _, method := range stmt.methods {
+		if declared[method.name.lexme] {
+			r.context.tokenError(method.name, "Method '%s' is already declared in this interface.", method.name.lexme)
+		}
+		declared[method.name.lexme] = true
+	}
+	return nil
+}
+
+// resolveInterfaces resolves names of interfaces implemented by class.
+func (r *Resolver) resolveInterfaces(stmt *ClassStmt) {
+	used := make(map[string]bool)
+	for _, iface := range stmt.interfaces {
+		if used[iface.name.lexme] {
+			r.context.tokenError(iface.name, "Interface '%s' is implemented more than once.", iface.name.lexme)
+		}
+		used[iface.name.lexme] = true
+		r.resolveExpr(iface)
+	}
+}
+
+// checkAbstract reports abstract methods, which are also declared with body
+// in the same class.
+func (r *Resolver) checkAbstract(stmt *ClassStmt) {
+	declared := make(map[string]bool)
+	for _, methods := range [][]*FunctionExpr{stmt.methods, stmt.getters, stmt.setters} {
+		for _, method := range methods {
+			if method.name != nil {
+				declared[method.name.lexme] = true
+			}
+		}
+	}
+
+	for _, method := range stmt.abstractMethods {
+		if declared[method.name.lexme] {
+			r.context.tokenError(method.name, "Abstract method '%s' is also declared with a body.", method.name.lexme)
+		}
+	}
+}
@@ -3,6 +3,8 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
+	"sync"
 )
 
 type LoopBodyResult = string
@@ -18,6 +20,14 @@ type Interpreter struct {
 	globals     *Environment
 	locals      map[Expr]int
 	includes    map[Stmt]*Source
+
+	// generator is the generator whose body is being executed.
+	generator *coroutine
+
+	// abandoned are coroutines of garbage collected generators, which are
+	// queued by finalizers to be closed.
+	abandoned     []*coroutine
+	abandonedLock sync.Mutex
 }
 
 func MakeInterpreter(context *LoxContext) *Interpreter {
@@ -59,61 +69,53 @@ func (i *Interpreter) interpret(statements []Stmt) {
 func (i *Interpreter) visitBinaryExpr(expr *BinaryExpr) Any {
 	left := i.evaluate(expr.left)
 	right := i.evaluate(expr.right)
+	return i.binary(expr.operator, left, right)
+}
 
-	switch expr.operator.tokenType {
-	case MINUS:
-		i.checkNumberOperands(expr.operator, left, right)
-		return left.(float) - right.(float)
+func (i *Interpreter) binary(operator *Token, left Any, right Any) Any {
+	if result, ok := i.overloaded(operator, left, right); ok {
+		return result
+	}
 
-	case SLASH:
-		i.checkNumberOperands(expr.operator, left, right)
-		return left.(float) / right.(float)
+	switch operator.tokenType {
+	case MINUS, SLASH, TILDE_SLASH, PERCENT, STAR, STAR_STAR:
+		return i.arithmetic(operator, left, right)
 
-	case STAR:
-		i.checkNumberOperands(expr.operator, left, right)
-		return left.(float) * right.(float)
+	case AMPERSAND, PIPE, CARET, LESS_LESS, GREATER_GREATER:
+		return i.bitwise(operator, left, right)
 
 	case PLUS:
-		switch leftVal := left.(type) {
-		case float:
-			switch rightVal := right.(type) {
-			case float:
-				return leftVal + rightVal
-			case string:
-				return fmt.Sprintf("%v%s", leftVal, rightVal)
-			}
-		case string:
-			switch rightVal := right.(type) {
-			case string:
-				return leftVal + rightVal
-			case float:
-				return fmt.Sprintf("%s%v", leftVal, rightVal)
-			}
+		if isNumber(left) && isNumber(right) {
+			return i.arithmetic(operator, left, right)
+		}
+		// Strings are concatenated with strings and numbers only, other
+		// values are converted by interpolation.
+		if (isString(left) || isNumber(left)) && (isString(right) || isNumber(right)) {
+			return stringify(left) + stringify(right)
 		}
-		i.context.runtimeError(expr.operator, "Operands must be two numbers or two strings.")
+		i.context.runtimeError(operator, "Operands must be two numbers or two strings.")
 		break
 
 	case GREATER:
-		i.checkNumberOperands(expr.operator, left, right)
-		return left.(float) > right.(float)
+		return i.compare(operator, left, right)
 
 	case GREATER_EQUAL:
-		i.checkNumberOperands(expr.operator, left, right)
-		return left.(float) >= right.(float)
+		return i.compare(operator, left, right)
 
 	case LESS:
-		i.checkNumberOperands(expr.operator, left, right)
-		return left.(float) < right.(float)
+		return i.compare(operator, left, right)
 
 	case LESS_EQUAL:
-		i.checkNumberOperands(expr.operator, left, right)
-		return left.(float) <= right.(float)
+		return i.compare(operator, left, right)
+
+	case IS:
+		return i.isInstance(operator, left, right)
 
 	case EQUAL_EQUAL:
-		return isEqual(left, right)
+		return i.isEqual(operator, left, right)
 
 	case BANG_EQUAL:
-		return !isEqual(left, right)
+		return !i.isEqual(operator, left, right)
 	}
 
 	return nil
@@ -123,17 +125,31 @@ func (i *Interpreter) visitGroupingExpr(expr *GroupingExpr) Any {
 	return i.evaluate(expr.expression)
 }
 
+// visitInterpolationExpr converts embedded values to text the same way
+// print does, without calling overloaded operators.
+func (i *Interpreter) visitInterpolationExpr(expr *InterpolationExpr) Any {
+	var text strings.Builder
+	for _, part := range expr.parts {
+		text.WriteString(i.stringify(i.evaluate(part)))
+	}
+	return text.String()
+}
+
 func (i *Interpreter) visitLiteralExpr(expr *LiteralExpr) Any {
 	return expr.value
 }
 
 func (i *Interpreter) visitUnaryExpr(expr *UnaryExpr) Any {
 	right := i.evaluate(expr.right)
+	if result, ok := i.overloadedUnary(expr.operator, right); ok {
+		return result
+	}
 
 	switch expr.operator.tokenType {
 	case MINUS:
-		i.checkNumberOperand(expr.operator, right)
-		return -right.(float)
+		return i.negate(expr.operator, right)
+	case TILDE:
+		return i.complement(expr.operator, right)
 	case BANG:
 		return !isTruthy(right)
 	}
@@ -142,16 +158,64 @@ func (i *Interpreter) visitUnaryExpr(expr *UnaryExpr) Any {
 }
 
 func (i *Interpreter) visitAssignExpr(expr *AssignExpr) Any {
-	value := i.evaluate(expr.value)
+	var value Any
+	if expr.operator != nil {
+		current := i.lookUpVariable(expr.name, expr)
+		value = i.binary(expr.operator, current, i.evaluate(expr.value))
+	} else {
+		value = i.evaluate(expr.value)
+	}
 
+	i.assignVariable(expr.name, expr, value)
+	return value
+}
+
+func (i *Interpreter) assignVariable(name *Token, expr Expr, value Any) {
 	distance, ok := i.locals[expr]
 	if ok {
-		i.environment.assignAt(distance, expr.name.lexme, value)
+		i.environment.assignAt(distance, name.lexme, value)
 	} else {
-		i.globals.assign(expr.name, value)
+		i.globals.assign(name, value)
 	}
+}
 
-	return value
+func (i *Interpreter) visitUpdateExpr(expr *UpdateExpr) Any {
+	var current Any
+	var assign func(value Any)
+
+	// Evaluate the target object (and index) only once.
+	switch target := expr.target.(type) {
+	case *VariableExpr:
+		current = i.lookUpVariable(target.name, expr)
+		assign = func(value Any) {
+			i.assignVariable(target.name, expr, value)
+		}
+		break
+	case *GetExpr:
+		object := i.evaluate(target.object)
+		current = i.getProperty(target.name, object)
+		assign = func(value Any) {
+			i.setProperty(target.name, object, value)
+		}
+		break
+	case *IndexExpr:
+		object := i.evaluate(target.object)
+		index := i.evaluate(target.index)
+		current = i.getIndex(target.bracket, object, index)
+		assign = func(value Any) {
+			i.setIndex(target.bracket, object, index, value)
+		}
+		break
+	}
+
+	i.checkNumberOperand(expr.operator, current)
+	value := i.binary(expr.operator, current, int64(1))
+	assign(value)
+
+	if expr.prefix {
+		return value
+	}
+	return current
 }
 
 func (i *Interpreter) visitVariableExpr(expr *VariableExpr) Any {
@@ -168,6 +232,19 @@ func (i *Interpreter) lookUpVariable(name *Token, expr Expr) Any {
 	}
 }
 
+// stringify converts value to the text used by print statements and string
+// concatenation.
+func stringify(value Any) string {
+	switch value := value.(type) {
+	case nil:
+		return "nil"
+	case string:
+		return value
+	default:
+		return fmt.Sprintf("%v", value)
+	}
+}
+
 func isTruthy(value Any) bool {
 	if value == nil {
 		return false
@@ -185,31 +262,19 @@ func isEqual(a Any, b Any) bool {
 	if a == nil {
 		return false
 	}
-	return a == b
-}
-
-func (i *Interpreter) checkNumberOperand(operator *Token, operand Any) {
-	switch operand.(type) {
-	case float:
-		return
+	if isNumber(a) && isNumber(b) {
+		return numbersEqual(a, b)
 	}
-	i.context.runtimeError(operator, "Operand must be a number.")
-}
-
-func (i *Interpreter) checkNumberOperands(operator *Token, left Any, right Any) {
-	switch left.(type) {
-	case float:
-		switch right.(type) {
-		case float:
-			return
-		}
+	if member, ok := a.(*LoxEnumMember); ok {
+		other, ok := b.(*LoxEnumMember)
+		return ok && member.equals(other)
 	}
-	i.context.runtimeError(operator, "Operands must be a numbers.")
+	return a == b
 }
 
 func (i *Interpreter) visitPrintStmt(stmt *PrintStmt) Any {
 	value := i.evaluate(stmt.expression)
-	fmt.Fprintf(os.Stdout, "%v\n", value)
+	fmt.Fprintln(os.Stdout, i.stringify(value))
 	return nil
 }
 
@@ -224,7 +289,7 @@ func (i *Interpreter) visitVarStmt(stmt *VarStmt) Any {
 		value = i.evaluate(stmt.initializer)
 	}
 
-	i.environment.define(stmt.name.lexme, value)
+	i.environment.declare(stmt.name, value, stmt.constant)
 	return nil
 }
 
@@ -251,6 +316,17 @@ func (i *Interpreter) executeBlock(statements []Stmt, environment *Environment)
 	}
 }
 
+// evaluateIn evaluates expression within given environment.
+func (i *Interpreter) evaluateIn(expr Expr, environment *Environment) Any {
+	previous := i.environment
+	defer func() {
+		i.environment = previous
+	}()
+	i.environment = environment
+
+	return i.evaluate(expr)
+}
+
 func (i *Interpreter) visitIfStmt(stmt *IfStmt) Any {
 	if isTruthy(i.evaluate(stmt.condition)) {
 		i.execute(stmt.thenBranch)
@@ -267,6 +343,10 @@ func (i *Interpreter) visitLogicalExpr(expr *LogicalExpr) Any {
 		if isTruthy(left) {
 			return left
 		}
+	} else if expr.operator.tokenType == QUESTION_QUESTION {
+		if left != nil {
+			return left
+		}
 	} else {
 		if !isTruthy(left) {
 			return left
@@ -278,7 +358,7 @@ func (i *Interpreter) visitLogicalExpr(expr *LogicalExpr) Any {
 
 func (i *Interpreter) visitWhileStmt(stmt *WhileStmt) Any {
 	for isTruthy(i.evaluate(stmt.condition)) {
-		if i.loopBody(stmt.body) == LOOP_BREAK {
+		if i.loopBody(stmt.body, i.environment) == LOOP_BREAK {
 			break
 		}
 	}
@@ -306,7 +386,7 @@ func (i *Interpreter) visitForStmt(stmt *ForStmt) Any {
 	}
 
 	for initializer(); condition(); increment() {
-		if i.loopBody(stmt.body) == LOOP_BREAK {
+		if i.loopBody(stmt.body, i.environment) == LOOP_BREAK {
 			break
 		}
 	}
@@ -321,7 +401,7 @@ func (j LoopJump) String() string {
 	return j.jumpType
 }
 
-func (i *Interpreter) loopBody(body Stmt) (result LoopBodyResult) {
+func (i *Interpreter) loopBody(body Stmt, environment *Environment) (result LoopBodyResult) {
 	defer func() {
 		if r := recover(); r != nil {
 			switch r := r.(type) {
@@ -334,7 +414,7 @@ func (i *Interpreter) loopBody(body Stmt) (result LoopBodyResult) {
 		}
 	}()
 
-	i.execute(body)
+	i.executeBlock([]Stmt{body}, environment)
 	return LOOP_CONTINUE
 }
 
@@ -348,7 +428,28 @@ func (i *Interpreter) visitContinueStmt(stmt *ContinueStmt) Any {
 
 type LoxCallable interface {
 	Call(interpreter *Interpreter, arguments []Any) Any
-	Arity() int
+	Arity() Arity
+}
+
+// Arity is the range of accepted argument counts, max is -1 for functions
+// with rest parameter.
+type Arity struct {
+	min int
+	max int
+}
+
+func (a Arity) accepts(count int) bool {
+	return count >= a.min && (a.max < 0 || count <= a.max)
+}
+
+func (a Arity) String() string {
+	if a.max < 0 {
+		return fmt.Sprintf("at least %v", a.min)
+	}
+	if a.min != a.max {
+		return fmt.Sprintf("%v to %v", a.min, a.max)
+	}
+	return fmt.Sprintf("%v", a.min)
 }
 
 type LoxCallableHandler = func(interpreter *Interpreter, arguments []Any) Any
@@ -366,35 +467,189 @@ func (c *LoxStaticCallable) Call(interpreter *Interpreter, arguments []Any) Any
 	return c.handler(interpreter, arguments)
 }
 
-func (c *LoxStaticCallable) Arity() int {
-	return c.arity
+func (c *LoxStaticCallable) Arity() Arity {
+	return Arity{c.arity, c.arity}
+}
+
+func (c *LoxStaticCallable) String() string {
+	return "<native fn>"
+}
+
+func (i *Interpreter) visitConditionalExpr(expr *ConditionalExpr) Any {
+	if isTruthy(i.evaluate(expr.condition)) {
+		return i.evaluate(expr.thenBranch)
+	}
+	return i.evaluate(expr.elseBranch)
 }
 
 func (i *Interpreter) visitCallExpr(expr *CallExpr) Any {
-	callee := i.evaluate(expr.callee)
+	value, _ := i.chain(expr)
+	return value
+}
 
-	switch val := callee.(type) {
-	case LoxCallable:
-		if val.Arity() != len(expr.arguments) {
-			i.context.runtimeError(expr.paren, "Expected %v arguments but got %v.", val.Arity(), len(expr.arguments))
+// chain evaluates link of a chain of property accesses, indexes, slices and
+// calls. Once "?." finds nil, the rest of the chain is skipped, including
+// arguments of calls, and the chain results in nil. Skipping is reported
+// by the second result, so that "obj?.a.b" is nil too.
+func (i *Interpreter) chain(expr Expr) (Any, bool) {
+	switch expr := expr.(type) {
+	case *GetExpr:
+		object, skipped := i.chain(expr.object)
+		if skipped || expr.optional && object == nil {
+			return nil, true
+		}
+		return i.getProperty(expr.name, object), false
+	case *IndexExpr:
+		object, skipped := i.chain(expr.object)
+		if skipped {
+			return nil, true
 		}
+		return i.getIndex(expr.bracket, object, i.evaluate(expr.index)), false
+	case *SliceExpr:
+		object, skipped := i.chain(expr.object)
+		if skipped {
+			return nil, true
+		}
+		return i.slice(expr, object), false
+	case *CallExpr:
+		callee, skipped := i.chain(expr.callee)
+		if skipped {
+			return nil, true
+		}
+		return i.call(expr, callee), false
+	}
+	return i.evaluate(expr), false
+}
 
-		arguments := make([]Any, len(expr.arguments))
+func (i *Interpreter) call(expr *CallExpr, callee Any) Any {
+	switch val := i.callable(callee).(type) {
+	case LoxCallable:
+		arguments := make([]Any, 0, len(expr.arguments))
 		for index, argument := range expr.arguments {
-			arguments[index] = i.evaluate(argument)
+			if expr.names[index] != nil {
+				arguments = i.namedArguments(expr, index, val, arguments)
+				break
+			}
+			if spread, ok := argument.(*SpreadExpr); ok {
+				arguments = append(arguments, i.spread(spread)...)
+			} else {
+				arguments = append(arguments, i.evaluate(argument))
+			}
+		}
+
+		if arity := val.Arity(); !arity.accepts(len(arguments)) {
+			i.context.runtimeError(expr.paren, "Expected %v arguments but got %v.", arity, len(arguments))
+		}
+
+		switch val.(type) {
+		case *LoxStaticCallable, *LoxClass:
+			return i.callNative(expr.paren, val, arguments)
 		}
 
 		return val.Call(i, arguments)
+	case *LoxInstance:
+		i.context.runtimeError(expr.paren, "'%s' instance can't be called, it has no '__call__' method.", val.klass.name)
+		return nil
 	default:
 		i.context.runtimeError(expr.paren, "Can only call functions and classes.")
 		return nil
 	}
 }
 
+// skippedArgument is passed in place of parameters skipped by named
+// arguments, so that their default values are used.
+type skippedArgument struct{}
+
+// namedArguments evaluates named arguments of the call, starting at given
+// index, and places them after positional ones in the order of callee
+// parameters.
+func (i *Interpreter) namedArguments(expr *CallExpr, named int, callee LoxCallable, positional []Any) []Any {
+	var declaration *FunctionExpr
+	switch callee := callee.(type) {
+	case *LoxFunction:
+		declaration = callee.declaration
+		break
+	case *LoxClass:
+		if initializer := callee.findMethod("init"); initializer != nil {
+			declaration = initializer.declaration
+		}
+		break
+	}
+
+	if declaration == nil {
+		i.context.runtimeError(expr.names[named], "Named arguments can only be passed to Lox functions and classes.")
+	}
+
+	first := len(positional)
+	if first > len(declaration.params) {
+		i.context.runtimeError(expr.names[named], "Named arguments can't follow rest arguments.")
+	}
+
+	arguments := append(positional, make([]Any, len(declaration.params)-first)...)
+	for index := first; index < len(arguments); index++ {
+		arguments[index] = skippedArgument{}
+	}
+
+	last := first
+	for index := named; index < len(expr.arguments); index++ {
+		name := expr.names[index]
+
+		position := -1
+		for param, token := range declaration.params {
+			if token.lexme == name.lexme {
+				position = param
+				break
+			}
+		}
+
+		if position < 0 {
+			i.context.runtimeError(name, "Unknown parameter '%s'.", name.lexme)
+		}
+		if position < first || arguments[position] != (skippedArgument{}) {
+			i.context.runtimeError(name, "Argument for parameter '%s' is already given.", name.lexme)
+		}
+
+		arguments[position] = i.evaluate(expr.arguments[index])
+		if position+1 > last {
+			last = position + 1
+		}
+	}
+
+	for index := first; index < len(arguments); index++ {
+		if arguments[index] == (skippedArgument{}) && declaration.defaults[index] == nil {
+			i.context.runtimeError(expr.paren, "Missing argument for parameter '%s'.", declaration.params[index].lexme)
+		}
+	}
+
+	return arguments[:last]
+}
+
+// callNative calls native function or class, reporting their NativeError at
+// paren.
+func (i *Interpreter) callNative(paren *Token, native LoxCallable, arguments []Any) Any {
+	defer func() {
+		if r := recover(); r != nil {
+			switch r := r.(type) {
+			case NativeError:
+				i.context.runtimeError(paren, "%s", r.message)
+				break
+			default:
+				panic(r)
+			}
+		}
+	}()
+
+	return native.Call(i, arguments)
+}
+
 type LoxFunction struct {
 	declaration   *FunctionExpr
 	closure       *Environment
 	isInitializer bool
+
+	// klass is class declaring the method, it gives access to private
+	// members of the class.
+	klass *LoxClass
 }
 
 func MakeLoxFunction(declaration *FunctionExpr, closure *Environment, isInitializer bool) *LoxFunction {
@@ -408,17 +663,70 @@ func MakeLoxFunction(declaration *FunctionExpr, closure *Environment, isInitiali
 func (f *LoxFunction) bind(instance *LoxInstance) *LoxFunction {
 	environment := f.closure.extend()
 	environment.define("this", instance)
-	return MakeLoxFunction(f.declaration, environment, f.isInitializer)
+	if f.klass != nil {
+		environment.define(PRIVATE_CLASS, f.klass)
+	}
+
+	function := MakeLoxFunction(f.declaration, environment, f.isInitializer)
+	function.klass = f.klass
+	return function
+}
+
+func (f *LoxFunction) String() string {
+	if f.declaration.name == nil {
+		return "<fn>"
+	}
+	return fmt.Sprintf("<fn %s>", f.declaration.name.lexme)
+}
+
+func (f *LoxFunction) Arity() Arity {
+	required := 0
+	for _, value := range f.declaration.defaults {
+		if value == nil {
+			required++
+		}
+	}
+
+	if f.declaration.rest != nil {
+		return Arity{required, -1}
+	}
+	return Arity{required, len(f.declaration.params)}
 }
 
-func (f *LoxFunction) Arity() int {
-	return len(f.declaration.params)
+func (f *LoxFunction) Call(interpreter *Interpreter, arguments []Any) Any {
+	if f.declaration.generator {
+		return MakeLoxGenerator(interpreter, f, arguments)
+	}
+	return f.execute(interpreter, arguments)
 }
 
-func (f *LoxFunction) Call(interpreter *Interpreter, arguments []Any) (result Any) {
+// execute runs body of the function with given arguments.
+func (f *LoxFunction) execute(interpreter *Interpreter, arguments []Any) (result Any) {
 	environment := f.closure.extend()
 	for index, param := range f.declaration.params {
-		environment.define(param.lexme, arguments[index])
+		var value Any = skippedArgument{}
+		if index < len(arguments) {
+			value = arguments[index]
+		}
+
+		// Default values are evaluated on each call and can refer to the
+		// preceding parameters.
+		if value == (skippedArgument{}) {
+			value = nil
+			if expr := f.declaration.defaults[index]; expr != nil {
+				value = interpreter.evaluateIn(expr, environment)
+			}
+		}
+
+		environment.define(param.lexme, value)
+	}
+
+	if rest := f.declaration.rest; rest != nil {
+		elements := make([]Any, 0)
+		if len(arguments) > len(f.declaration.params) {
+			elements = append(elements, arguments[len(f.declaration.params):]...)
+		}
+		environment.define(rest.lexme, MakeLoxList(elements))
 	}
 
 	defer func() {
@@ -449,7 +757,7 @@ func (f *LoxFunction) Call(interpreter *Interpreter, arguments []Any) (result An
 func (i *Interpreter) visitFunctionExpr(expr *FunctionExpr) Any {
 	function := MakeLoxFunction(expr, i.environment, false)
 	if expr.name != nil {
-		i.environment.define(expr.name.lexme, function)
+		i.environment.declare(expr.name, function, false)
 	}
 
 	return function
@@ -480,7 +788,10 @@ func (i *Interpreter) visitClassStmt(stmt *ClassStmt) Any {
 		}
 	}
 
-	i.environment.define(stmt.name.lexme, nil)
+	traits := i.traits(stmt)
+	interfaces := i.interfaces(stmt)
+
+	i.environment.declare(stmt.name, nil, false)
 
 	if superclass != nil {
 		i.environment = i.environment.extend()
@@ -492,14 +803,48 @@ func (i *Interpreter) visitClassStmt(stmt *ClassStmt) Any {
 		function := MakeLoxFunction(method, i.environment, method.name.lexme == "init")
 		methods[method.name.lexme] = function
 	}
+	i.composeTraits(stmt, traits, methods)
+
+	klass := MakeLoxClass(i.context, stmt.name.lexme, superclass, methods, i.environment)
+	klass.traits = traits
+	klass.interfaces = interfaces
+	klass.abstractMethods = stmt.abstractMethods
+	klass.fields = stmt.fields
+	for _, method := range methods {
+		method.klass = klass
+	}
 
-	klass := MakeLoxClass(i.context, stmt.name.lexme, superclass, methods)
+	for _, method := range stmt.staticMethods {
+		klass.staticMethods[method.name.lexme] = MakeLoxFunction(method, i.environment, false)
+	}
+	for _, getter := range stmt.getters {
+		function := MakeLoxFunction(getter, i.environment, false)
+		function.klass = klass
+		klass.getters[getter.name.lexme] = function
+	}
+	for _, setter := range stmt.setters {
+		function := MakeLoxFunction(setter, i.environment, false)
+		function.klass = klass
+		klass.setters[setter.name.lexme] = function
+	}
 
 	if superclass != nil {
 		i.environment = i.environment.enclosing
 	}
 
+	i.checkInterfaces(stmt, klass)
 	i.environment.assign(stmt.name, klass)
+
+	// Static fields are initialized once the class is defined, so they can
+	// hold its instances.
+	for _, field := range stmt.staticFields {
+		var value Any = nil
+		if field.initializer != nil {
+			value = i.evaluateIn(field.initializer, klass.closure)
+		}
+		klass.staticFields[field.name.lexme] = value
+	}
+
 	return nil
 }
 
@@ -508,14 +853,35 @@ type LoxClass struct {
 	name       string
 	superclass *LoxClass
 	methods    map[string]*LoxFunction
+	traits     []*LoxTrait
+	interfaces []*LoxInterface
+	getters    map[string]*LoxFunction
+	setters    map[string]*LoxFunction
+
+	// abstractMethods are declared without body, the class can't be
+	// instantiated until subclasses implement them.
+	abstractMethods []*FunctionExpr
+
+	// closure is environment the class was declared in, including "super",
+	// field initializers are evaluated in it.
+	closure       *Environment
+	fields        []*VarStmt
+	staticMethods map[string]*LoxFunction
+	staticFields  map[string]Any
 }
 
-func MakeLoxClass(context *LoxContext, name string, superclass *LoxClass, methods map[string]*LoxFunction) *LoxClass {
+func MakeLoxClass(context *LoxContext, name string, superclass *LoxClass, methods map[string]*LoxFunction, closure *Environment) *LoxClass {
 	return &LoxClass{
-		context:    context,
-		name:       name,
-		superclass: superclass,
-		methods:    methods,
+		context:       context,
+		name:          name,
+		superclass:    superclass,
+		methods:       methods,
+		getters:       make(map[string]*LoxFunction),
+		setters:       make(map[string]*LoxFunction),
+		closure:       closure,
+		fields:        make([]*VarStmt, 0),
+		staticMethods: make(map[string]*LoxFunction),
+		staticFields:  make(map[string]Any),
 	}
 }
 
@@ -523,16 +889,21 @@ func (c *LoxClass) String() string {
 	return c.name
 }
 
-func (c *LoxClass) Arity() int {
+func (c *LoxClass) Arity() Arity {
 	if initializer := c.findMethod("init"); initializer != nil {
 		return initializer.Arity()
 	}
 
-	return 0
+	return Arity{0, 0}
 }
 
 func (c *LoxClass) Call(interpreter *Interpreter, arguments []Any) Any {
+	if missing := c.unimplemented(); len(missing) > 0 {
+		nativeError("Can't instantiate abstract class '%s', it doesn't implement %s.", c.name, strings.Join(missing, ", "))
+	}
+
 	instance := MakeLoxInstance(c)
+	c.initializeFields(interpreter, instance)
 	if initializer := c.findMethod("init"); initializer != nil {
 		initializer.bind(instance).Call(interpreter, arguments)
 	}
@@ -540,13 +911,76 @@ func (c *LoxClass) Call(interpreter *Interpreter, arguments []Any) Any {
 	return instance
 }
 
-func (c *LoxClass) findMethod(name string) *LoxFunction {
-	if method, ok := c.methods[name]; ok {
-		return method
+// initializeFields sets declared fields of instance, fields of superclasses
+// are initialized first.
+func (c *LoxClass) initializeFields(interpreter *Interpreter, instance *LoxInstance) {
+	if c.superclass != nil {
+		c.superclass.initializeFields(interpreter, instance)
+	}
+	if len(c.fields) == 0 {
+		return
 	}
 
-	if c.superclass != nil {
-		return c.superclass.findMethod(name)
+	environment := c.closure.extend()
+	environment.define("this", instance)
+	environment.define(PRIVATE_CLASS, c)
+	for _, field := range c.fields {
+		var value Any = nil
+		if field.initializer != nil {
+			value = interpreter.evaluateIn(field.initializer, environment)
+		}
+		if isPrivate(field.name) {
+			instance.privateFields(c)[field.name.lexme] = value
+		} else {
+			instance.fields[field.name.lexme] = value
+		}
+	}
+}
+
+// get returns static field or static method of the class or of its
+// superclasses.
+func (c *LoxClass) get(interpreter *Interpreter, name *Token) Any {
+	for klass := c; klass != nil; klass = klass.superclass {
+		if value, ok := klass.staticFields[name.lexme]; ok {
+			return value
+		}
+		if method, ok := klass.staticMethods[name.lexme]; ok {
+			return method
+		}
+	}
+
+	interpreter.context.runtimeError(name, "Undefined static property '%s'.", name.lexme)
+	return nil
+}
+
+func (c *LoxClass) isSubclassOf(other *LoxClass) bool {
+	for klass := c; klass != nil; klass = klass.superclass {
+		if klass == other {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *LoxClass) findMethod(name string) *LoxFunction {
+	return c.findMember(name, func(klass *LoxClass) map[string]*LoxFunction { return klass.methods })
+}
+
+func (c *LoxClass) findGetter(name string) *LoxFunction {
+	return c.findMember(name, func(klass *LoxClass) map[string]*LoxFunction { return klass.getters })
+}
+
+func (c *LoxClass) findSetter(name string) *LoxFunction {
+	return c.findMember(name, func(klass *LoxClass) map[string]*LoxFunction { return klass.setters })
+}
+
+// findMember looks up function in members of the class, or of its
+// superclasses.
+func (c *LoxClass) findMember(name string, members func(klass *LoxClass) map[string]*LoxFunction) *LoxFunction {
+	for klass := c; klass != nil; klass = klass.superclass {
+		if function, ok := members(klass)[name]; ok {
+			return function
+		}
 	}
 
 	return nil
@@ -555,6 +989,11 @@ func (c *LoxClass) findMethod(name string) *LoxFunction {
 type LoxInstance struct {
 	klass  *LoxClass
 	fields map[string]Any
+	frozen bool
+
+	// privates holds private fields keyed by the declaring class, so
+	// subclasses can declare private fields of the same name.
+	privates map[*LoxClass]map[string]Any
 }
 
 func MakeLoxInstance(klass *LoxClass) *LoxInstance {
@@ -564,11 +1003,15 @@ func MakeLoxInstance(klass *LoxClass) *LoxInstance {
 	}
 }
 
-func (i *LoxInstance) get(name *Token) Any {
+func (i *LoxInstance) get(interpreter *Interpreter, name *Token) Any {
 	if value, ok := i.fields[name.lexme]; ok {
 		return value
 	}
 
+	if getter := i.klass.findGetter(name.lexme); getter != nil {
+		return getter.bind(i).Call(interpreter, []Any{})
+	}
+
 	if method := i.klass.findMethod(name.lexme); method != nil {
 		return method.bind(i)
 	}
@@ -586,28 +1029,65 @@ func (i *LoxInstance) String() string {
 }
 
 func (i *Interpreter) visitGetExpr(expr *GetExpr) Any {
-	object := i.evaluate(expr.object)
+	value, _ := i.chain(expr)
+	return value
+}
+
+func (i *Interpreter) getProperty(name *Token, object Any) Any {
+	if isPrivate(name) {
+		return i.getPrivate(name, object)
+	}
+
 	switch object := object.(type) {
-	case *LoxInstance:
-		return object.get(expr.name)
+	case LoxObject:
+		return object.get(i, name)
 	}
 
-	i.context.runtimeError(expr.name, "Only instances have properties.")
+	i.context.runtimeError(name, "Only instances have properties.")
 	return nil
 }
 
 func (i *Interpreter) visitSetExpr(expr *SetExpr) Any {
 	object := i.evaluate(expr.object)
 
+	var value Any
+	if expr.operator != nil {
+		current := i.getProperty(expr.name, object)
+		value = i.binary(expr.operator, current, i.evaluate(expr.value))
+	} else {
+		value = i.evaluate(expr.value)
+	}
+
+	i.setProperty(expr.name, object, value)
+	return value
+}
+
+func (i *Interpreter) setProperty(name *Token, object Any, value Any) {
+	if isPrivate(name) {
+		i.setPrivate(name, object, value)
+		return
+	}
+
 	switch object := object.(type) {
 	case *LoxInstance:
-		value := i.evaluate(expr.value)
-		object.set(expr.name, value)
-		return nil
+		if object.frozen {
+			i.context.runtimeError(name, "Can't modify frozen '%s' instance.", object.klass.name)
+		}
+		if setter := object.klass.findSetter(name.lexme); setter != nil {
+			setter.bind(object).Call(i, []Any{value})
+			return
+		}
+		if object.klass.findGetter(name.lexme) != nil {
+			i.context.runtimeError(name, "Property '%s' of '%s' instance has no setter.", name.lexme, object.klass.name)
+		}
+		object.set(name, value)
+		return
+	case *LoxClass:
+		object.staticFields[name.lexme] = value
+		return
 	}
 
-	i.context.runtimeError(expr.name, "Only instances have fields.")
-	return nil
+	i.context.runtimeError(name, "Only instances have fields.")
 }
 
 func (i *Interpreter) visitThisExpr(expr *ThisExpr) Any {
@@ -622,6 +1102,9 @@ func (i *Interpreter) visitSuperExpr(expr *SuperExpr) Any {
 
 	method := superclass.findMethod(expr.method.lexme)
 	if method == nil {
+		if getter := superclass.findGetter(expr.method.lexme); getter != nil {
+			return getter.bind(object).Call(i, []Any{})
+		}
 		i.context.runtimeError(expr.method, "Undefined property '%s'.", expr.method.lexme)
 	}
 
@@ -0,0 +1,139 @@
+package main
+
+// LoxIterable is implemented by values which can be iterated by for-in
+// loops.
+type LoxIterable interface {
+	iterator() LoxIterator
+}
+
+type LoxIterator interface {
+	hasNext() bool
+	next() Any
+}
+
+type listIterator struct {
+	list  *LoxList
+	index int
+}
+
+func (l *LoxList) iterator() LoxIterator {
+	return &listIterator{list: l}
+}
+
+func (it *listIterator) hasNext() bool {
+	return it.index < len(it.list.elements)
+}
+
+func (it *listIterator) next() Any {
+	element := it.list.elements[it.index]
+	it.index++
+	return element
+}
+
+// sliceIterator iterates over a snapshot of values, e.g. map keys.
+type sliceIterator struct {
+	values []Any
+	index  int
+}
+
+func (m *LoxMap) iterator() LoxIterator {
+	return &sliceIterator{values: m.keys()}
+}
+
+func (it *sliceIterator) hasNext() bool {
+	return it.index < len(it.values)
+}
+
+func (it *sliceIterator) next() Any {
+	value := it.values[it.index]
+	it.index++
+	return value
+}
+
+// instanceIterator drives instances implementing hasNext() and next()
+// methods.
+type instanceIterator struct {
+	interpreter *Interpreter
+	token       *Token
+	instance    *LoxInstance
+}
+
+func (it *instanceIterator) hasNext() bool {
+	return isTruthy(it.interpreter.callMethod(it.token, it.instance, "hasNext"))
+}
+
+func (it *instanceIterator) next() Any {
+	return it.interpreter.callMethod(it.token, it.instance, "next")
+}
+
+// iterate returns iterator over value. Instances either implement the
+// iterator protocol (hasNext() and next() methods) themselves, or return an
+// iterable value or an iterator instance from their iterator() method.
+func (i *Interpreter) iterate(token *Token, value Any) LoxIterator {
+	switch value := value.(type) {
+	case LoxIterable:
+		return value.iterator()
+
+	case *LoxGenerator:
+		return &generatorIterator{generator: value, token: token}
+
+	case string:
+		characters := make([]Any, 0, len(value))
+		for _, char := range value {
+			characters = append(characters, string(char))
+		}
+		return &sliceIterator{values: characters}
+
+	case *LoxInstance:
+		if value.klass.findMethod("hasNext") != nil && value.klass.findMethod("next") != nil {
+			return &instanceIterator{interpreter: i, token: token, instance: value}
+		}
+		if value.klass.findMethod("iterator") != nil {
+			iterator := i.callMethod(token, value, "iterator")
+			if iterator == value {
+				i.context.runtimeError(token, "Method 'iterator' of '%s' instance must return an iterator.", value.klass.name)
+			}
+			return i.iterate(token, iterator)
+		}
+		break
+	}
+
+	i.context.runtimeError(token, "Can only iterate over lists, maps, strings and iterable instances.")
+	return nil
+}
+
+// callMethod calls method of instance without arguments.
+func (i *Interpreter) callMethod(token *Token, instance *LoxInstance, name string) Any {
+	method := instance.klass.findMethod(name)
+	if method == nil {
+		i.context.runtimeError(token, "Undefined method '%s' of '%s' instance.", name, instance.klass.name)
+	}
+	if !method.Arity().accepts(0) {
+		i.context.runtimeError(token, "Method '%s' of '%s' instance must take no arguments.", name, instance.klass.name)
+	}
+
+	return method.bind(instance).Call(i, []Any{})
+}
+
+func (i *Interpreter) visitForInStmt(stmt *ForInStmt) Any {
+	iterator := i.iterate(stmt.keyword, i.evaluate(stmt.iterable))
+
+	// Generators left by break, return or an error are closed, so their
+	// bodies don't stay suspended forever.
+	if generator, ok := iterator.(*generatorIterator); ok {
+		defer generator.generator.close()
+	}
+
+	for iterator.hasNext() {
+		// Each iteration gets its own variable, so closures capture the
+		// value of the current element.
+		environment := i.environment.extend()
+		environment.define(stmt.name.lexme, iterator.next())
+
+		if i.loopBody(stmt.body, environment) == LOOP_BREAK {
+			break
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// Scripts in the test directory state their expected output in comments.
+// "// expect: text" is a line printed by the script and "// expect error:
+// message" is an error reported on the line of the comment, such as
+// "Error at 'x': Undefined variable 'x'.".
+var expectation = regexp.MustCompile(`// expect( error)?: (.*)$`)
+
+func TestScripts(t *testing.T) {
+	names, err := filepath.Glob(filepath.Join("test", "*.lox"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range names {
+		name := filepath.Base(name)
+		t.Run(strings.TrimSuffix(name, ".lox"), func(t *testing.T) {
+			testScript(t, name)
+		})
+	}
+}
+
+func testScript(t *testing.T, name string) {
+	contents, err := ioutil.ReadFile(filepath.Join("test", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedOutput := make([]string, 0)
+	expectedErrors := make([]string, 0)
+	for index, line := range strings.Split(string(contents), "\n") {
+		match := expectation.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		if match[1] == "" {
+			expectedOutput = append(expectedOutput, match[2])
+		} else {
+			expectedErrors = append(expectedErrors, fmt.Sprintf("[%s:%d] %s", name, index+1, match[2]))
+		}
+	}
+
+	var runErr error
+	output, errors := captureOutput(t, func() {
+		runErr = runScript(name)
+	})
+	if runErr != nil {
+		t.Fatal(runErr)
+	}
+
+	compareLines(t, "output", expectedOutput, output)
+	compareLines(t, "errors", expectedErrors, errors)
+}
+
+// runScript runs script the same way as golox does, except that runtime
+// errors stop the script without exiting.
+func runScript(name string) (err error) {
+	ctx := MakeContext()
+	interpreter := MakeInterpreter(ctx)
+	sourceResolver := MakeFileSourceResolver("test")
+
+	source, err := sourceResolver.Resolve(ctx, name)
+	if err != nil || ctx.hadError {
+		return err
+	}
+
+	resolver := MakeResolver(ctx, interpreter, sourceResolver)
+	resolver.resolve(source.Body)
+	if ctx.hadError {
+		return nil
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(RuntimeError); !ok {
+				panic(r)
+			}
+		}
+	}()
+	interpreter.interpret(source.Body)
+	return nil
+}
+
+// captureOutput returns lines written to standard output and standard error
+// while running function.
+func captureOutput(t *testing.T, function func()) ([]string, []string) {
+	stdout, stderr := os.Stdout, os.Stderr
+	defer func() {
+		os.Stdout, os.Stderr = stdout, stderr
+	}()
+
+	output := pipe(t, &os.Stdout)
+	errors := pipe(t, &os.Stderr)
+	function()
+	os.Stdout.Close()
+	os.Stderr.Close()
+
+	return lines(<-output), lines(<-errors)
+}
+
+// pipe replaces file by writing end of a pipe, and returns channel receiving
+// everything written to it once it's closed.
+func pipe(t *testing.T, file **os.File) <-chan string {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	*file = writer
+
+	result := make(chan string)
+	go func() {
+		var buffer bytes.Buffer
+		io.Copy(&buffer, reader)
+		reader.Close()
+		result <- buffer.String()
+	}()
+	return result
+}
+
+func lines(text string) []string {
+	if text == "" {
+		return []string{}
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+func compareLines(t *testing.T, kind string, expected []string, actual []string) {
+	for index := 0; index < len(expected) || index < len(actual); index++ {
+		switch {
+		case index >= len(actual):
+			t.Errorf("missing %s line %d: %q", kind, index+1, expected[index])
+		case index >= len(expected):
+			t.Errorf("unexpected %s line %d: %q", kind, index+1, actual[index])
+		case expected[index] != actual[index]:
+			t.Errorf("%s line %d: expected %q, got %q", kind, index+1, expected[index], actual[index])
+		}
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"os"
 )
@@ -39,7 +40,7 @@ func runFromStdin() {
 			switch val := statements[0].(type) {
 			case *ExpressionStmt:
 				result := interpreter.evaluate(val.expression)
-				fmt.Fprintf(os.Stdout, "%v\n", result)
+				fmt.Fprintln(os.Stdout, interpreter.stringify(result))
 				break
 			default:
 				interpreter.interpret(statements)
@@ -75,7 +76,38 @@ func runFromFile(name string) {
 	interpreter.interpret(source.Body)
 }
 
+func runDoc(args []string) {
+	flags := flag.NewFlagSet("doc", flag.ExitOnError)
+	output := flags.String("o", "docs", "output directory")
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		os.Stderr.WriteString("Syntax: golox doc [-o directory] source...")
+		os.Exit(64)
+	}
+
+	ctx := MakeContext()
+	generator := MakeDocGenerator(ctx, MakeFileSourceResolver(""))
+
+	for _, name := range flags.Args() {
+		if _, err := generator.collect(name); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(65)
+		}
+	}
+
+	if err := generator.write(*output); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(74)
+	}
+}
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "doc" {
+		runDoc(os.Args[2:])
+		return
+	}
+
 	switch len(os.Args) {
 	case 1:
 		runFromStdin()
@@ -0,0 +1,528 @@
+package main
+
+import (
+	"math"
+	"math/big"
+)
+
+// Lox numbers are integers, decimals or floating point numbers (float).
+//
+// Integers are int64 values which are transparently promoted to *big.Int
+// when the result doesn't fit into 64 bits, and demoted back when it does.
+// Decimals (*Decimal) are produced by literals like 12.34d and are exact.
+//
+// Arithmetic on two integers stays integral, except for "/" which always
+// produces a float. Integers are promoted to decimal or float when the other
+// operand is one, but decimals and floats can't be mixed, since that would
+// silently lose exactness.
+
+type numberKind int
+
+const (
+	NUMBER_NONE numberKind = iota
+	NUMBER_INTEGER
+	NUMBER_DECIMAL
+	NUMBER_FLOAT
+)
+
+func kindOfNumber(value Any) numberKind {
+	switch value.(type) {
+	case int64, *big.Int:
+		return NUMBER_INTEGER
+	case *Decimal:
+		return NUMBER_DECIMAL
+	case float:
+		return NUMBER_FLOAT
+	}
+	return NUMBER_NONE
+}
+
+func isNumber(value Any) bool {
+	return kindOfNumber(value) != NUMBER_NONE
+}
+
+func isInteger(value Any) bool {
+	return kindOfNumber(value) == NUMBER_INTEGER
+}
+
+func toFloat(value Any) float {
+	switch value := value.(type) {
+	case int64:
+		return float(value)
+	case *big.Int:
+		result, _ := new(big.Float).SetInt(value).Float64()
+		return result
+	case *Decimal:
+		result, _ := value.Rat().Float64()
+		return result
+	case float:
+		return value
+	}
+	return math.NaN()
+}
+
+func toBigInt(value Any) *big.Int {
+	switch value := value.(type) {
+	case int64:
+		return big.NewInt(value)
+	case *big.Int:
+		return value
+	}
+	return nil
+}
+
+func toDecimal(value Any) *Decimal {
+	switch value := value.(type) {
+	case int64, *big.Int:
+		return MakeDecimal(toBigInt(value), 0)
+	case *Decimal:
+		return value
+	}
+	return nil
+}
+
+// toRat converts integers and decimals to exact rational numbers.
+func toRat(value Any) *big.Rat {
+	switch value := value.(type) {
+	case int64, *big.Int:
+		return new(big.Rat).SetInt(toBigInt(value))
+	case *Decimal:
+		return value.Rat()
+	case float:
+		return new(big.Rat).SetFloat64(value)
+	}
+	return nil
+}
+
+// normalizeInteger demotes big integers which fit into int64.
+func normalizeInteger(value *big.Int) Any {
+	if value.IsInt64() {
+		return value.Int64()
+	}
+	return value
+}
+
+func addInt(a int64, b int64) (int64, bool) {
+	c := a + b
+	return c, (c > a) == (b > 0)
+}
+
+func subInt(a int64, b int64) (int64, bool) {
+	c := a - b
+	return c, (c < a) == (b > 0)
+}
+
+func mulInt(a int64, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	c := a * b
+	if c/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return c, false
+	}
+	return c, true
+}
+
+func powInt(base int64, exponent int64) (int64, bool) {
+	result := int64(1)
+	for exponent > 0 {
+		var ok bool
+		if exponent&1 == 1 {
+			if result, ok = mulInt(result, base); !ok {
+				return 0, false
+			}
+		}
+		exponent >>= 1
+		if exponent > 0 {
+			if base, ok = mulInt(base, base); !ok {
+				return 0, false
+			}
+		}
+	}
+	return result, true
+}
+
+// floorDivInt returns quotient of floored division, MinInt64 ~/ -1 is the
+// only case which overflows.
+func floorDivInt(a int64, b int64) (int64, bool) {
+	if a == math.MinInt64 && b == -1 {
+		return 0, false
+	}
+	quotient := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		quotient--
+	}
+	return quotient, true
+}
+
+// floorModInt returns remainder of floored division, so the result has the
+// same sign as the divisor.
+func floorModInt(a int64, b int64) int64 {
+	mod := a % b
+	if mod != 0 && (mod < 0) != (b < 0) {
+		mod += b
+	}
+	return mod
+}
+
+func floorDivBig(a *big.Int, b *big.Int) *big.Int {
+	quotient, remainder := new(big.Int).QuoRem(a, b, new(big.Int))
+	if remainder.Sign() != 0 && (remainder.Sign() < 0) != (b.Sign() < 0) {
+		quotient.Sub(quotient, big.NewInt(1))
+	}
+	return quotient
+}
+
+func floorModBig(a *big.Int, b *big.Int) *big.Int {
+	remainder := new(big.Int).Rem(a, b)
+	if remainder.Sign() != 0 && (remainder.Sign() < 0) != (b.Sign() < 0) {
+		remainder.Add(remainder, b)
+	}
+	return remainder
+}
+
+// floorMod returns remainder of floored division, so the result has the
+// same sign as the divisor.
+func floorMod(a float, b float) float {
+	mod := math.Mod(a, b)
+	if mod != 0 && (mod < 0) != (b < 0) {
+		mod += b
+	}
+	return mod
+}
+
+// arithmetic evaluates "+ - * / ~/ % **" operators on numeric operands.
+func (i *Interpreter) arithmetic(operator *Token, left Any, right Any) Any {
+	kind := i.checkNumberOperands(operator, left, right)
+
+	if operator.tokenType == SLASH || operator.tokenType == TILDE_SLASH || operator.tokenType == PERCENT {
+		i.checkDivisor(operator, right)
+	}
+
+	switch kind {
+	case NUMBER_INTEGER:
+		if operator.tokenType == STAR_STAR && toBigInt(right).Sign() < 0 {
+			i.checkDivisor(operator, left)
+		}
+		if leftVal, ok := left.(int64); ok {
+			if rightVal, ok := right.(int64); ok {
+				if result, ok := integerArithmetic(operator, leftVal, rightVal); ok {
+					return result
+				}
+			}
+		}
+		return i.bigIntegerArithmetic(operator, toBigInt(left), toBigInt(right))
+	case NUMBER_DECIMAL:
+		return i.decimalArithmetic(operator, toDecimal(left), toDecimal(right))
+	default:
+		return floatArithmetic(operator, toFloat(left), toFloat(right))
+	}
+}
+
+// integerArithmetic evaluates operator on int64 operands, it fails when the
+// result doesn't fit into int64.
+func integerArithmetic(operator *Token, a int64, b int64) (Any, bool) {
+	switch operator.tokenType {
+	case PLUS:
+		return addInt(a, b)
+	case MINUS:
+		return subInt(a, b)
+	case STAR:
+		return mulInt(a, b)
+	case SLASH:
+		return float(a) / float(b), true
+	case TILDE_SLASH:
+		return floorDivInt(a, b)
+	case PERCENT:
+		return floorModInt(a, b), true
+	case STAR_STAR:
+		if b < 0 {
+			return math.Pow(float(a), float(b)), true
+		}
+		return powInt(a, b)
+	}
+	return nil, true
+}
+
+func (i *Interpreter) bigIntegerArithmetic(operator *Token, a *big.Int, b *big.Int) Any {
+	switch operator.tokenType {
+	case PLUS:
+		return normalizeInteger(new(big.Int).Add(a, b))
+	case MINUS:
+		return normalizeInteger(new(big.Int).Sub(a, b))
+	case STAR:
+		return normalizeInteger(new(big.Int).Mul(a, b))
+	case SLASH:
+		result, _ := new(big.Rat).SetFrac(a, b).Float64()
+		return result
+	case TILDE_SLASH:
+		return normalizeInteger(floorDivBig(a, b))
+	case PERCENT:
+		return normalizeInteger(floorModBig(a, b))
+	case STAR_STAR:
+		if b.Sign() < 0 {
+			return math.Pow(toFloat(a), toFloat(b))
+		}
+		i.checkPowerSize(operator, a, b)
+		return normalizeInteger(new(big.Int).Exp(a, b, nil))
+	}
+	return nil
+}
+
+func (i *Interpreter) decimalArithmetic(operator *Token, a *Decimal, b *Decimal) Any {
+	switch operator.tokenType {
+	case PLUS:
+		return a.Add(b)
+	case MINUS:
+		return a.Sub(b)
+	case STAR:
+		return a.Mul(b)
+	case SLASH:
+		return a.Quo(b)
+	case TILDE_SLASH:
+		return a.FloorDiv(b)
+	case PERCENT:
+		return a.Mod(b)
+	case STAR_STAR:
+		if !b.IsInt() {
+			i.context.runtimeError(operator, "Exponent of a decimal must be an integer.")
+		}
+		exponent := b.Int()
+		i.checkPowerSize(operator, a.unscaled, exponent)
+		if a.scale > 0 && exponent.CmpAbs(big.NewInt(1<<16)) > 0 {
+			i.context.runtimeError(operator, "Result of exponentiation is too large.")
+		}
+
+		n := new(big.Int).Abs(exponent)
+		result := MakeDecimal(new(big.Int).Exp(a.unscaled, n, nil), a.scale*int(n.Int64()))
+		if exponent.Sign() < 0 {
+			i.checkDivisor(operator, result)
+			return MakeDecimal(big.NewInt(1), 0).Quo(result)
+		}
+		return result
+	}
+	return nil
+}
+
+// checkPowerSize rejects exponentiation which would need gigantic amount of
+// memory to store the result.
+func (i *Interpreter) checkPowerSize(operator *Token, base *big.Int, exponent *big.Int) {
+	if !exponent.IsInt64() || (base.BitLen() > 1 && int64(base.BitLen())*exponent.Int64() > 1<<24) {
+		i.context.runtimeError(operator, "Result of exponentiation is too large.")
+	}
+}
+
+func floatArithmetic(operator *Token, a float, b float) Any {
+	switch operator.tokenType {
+	case PLUS:
+		return a + b
+	case MINUS:
+		return a - b
+	case STAR:
+		return a * b
+	case SLASH:
+		return a / b
+	case TILDE_SLASH:
+		return math.Floor(a / b)
+	case PERCENT:
+		return floorMod(a, b)
+	case STAR_STAR:
+		return math.Pow(a, b)
+	}
+	return nil
+}
+
+// compare evaluates "< <= > >=" operators on numeric operands, members of
+// enum are compared by their ordinals.
+func (i *Interpreter) compare(operator *Token, left Any, right Any) bool {
+	if member, ok := left.(*LoxEnumMember); ok {
+		left, right = i.ordinals(operator, member, right)
+	}
+
+	if i.checkNumberOperands(operator, left, right) == NUMBER_FLOAT {
+		a, b := toFloat(left), toFloat(right)
+		switch operator.tokenType {
+		case GREATER:
+			return a > b
+		case GREATER_EQUAL:
+			return a >= b
+		case LESS:
+			return a < b
+		case LESS_EQUAL:
+			return a <= b
+		}
+		return false
+	}
+
+	var order int
+	leftVal, leftIsInt := left.(int64)
+	rightVal, rightIsInt := right.(int64)
+	if leftIsInt && rightIsInt {
+		order = compareInt(leftVal, rightVal)
+	} else {
+		order = toRat(left).Cmp(toRat(right))
+	}
+
+	switch operator.tokenType {
+	case GREATER:
+		return order > 0
+	case GREATER_EQUAL:
+		return order >= 0
+	case LESS:
+		return order < 0
+	case LESS_EQUAL:
+		return order <= 0
+	}
+	return false
+}
+
+func compareInt(a int64, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// numbersEqual compares numbers by their exact value, regardless of their
+// representation.
+func numbersEqual(a Any, b Any) bool {
+	leftVal, leftIsInt := a.(int64)
+	rightVal, rightIsInt := b.(int64)
+	if leftIsInt && rightIsInt {
+		return leftVal == rightVal
+	}
+
+	for _, value := range []Any{a, b} {
+		if value, ok := value.(float); ok && (math.IsNaN(value) || math.IsInf(value, 0)) {
+			return toFloat(a) == toFloat(b)
+		}
+	}
+
+	return toRat(a).Cmp(toRat(b)) == 0
+}
+
+func (i *Interpreter) negate(operator *Token, operand Any) Any {
+	i.checkNumberOperand(operator, operand)
+
+	switch operand := operand.(type) {
+	case int64:
+		if operand == math.MinInt64 {
+			return new(big.Int).Neg(big.NewInt(operand))
+		}
+		return -operand
+	case *big.Int:
+		return normalizeInteger(new(big.Int).Neg(operand))
+	case *Decimal:
+		return operand.Neg()
+	case float:
+		return -operand
+	}
+	return nil
+}
+
+// bitwise evaluates "& | ^ << >>" operators on integer operands.
+func (i *Interpreter) bitwise(operator *Token, left Any, right Any) Any {
+	i.checkIntegerOperands(operator, left, right)
+
+	leftVal, leftIsInt := left.(int64)
+	rightVal, rightIsInt := right.(int64)
+	if leftIsInt && rightIsInt {
+		switch operator.tokenType {
+		case AMPERSAND:
+			return leftVal & rightVal
+		case PIPE:
+			return leftVal | rightVal
+		case CARET:
+			return leftVal ^ rightVal
+		}
+	}
+
+	a, b := toBigInt(left), toBigInt(right)
+	switch operator.tokenType {
+	case AMPERSAND:
+		return normalizeInteger(new(big.Int).And(a, b))
+	case PIPE:
+		return normalizeInteger(new(big.Int).Or(a, b))
+	case CARET:
+		return normalizeInteger(new(big.Int).Xor(a, b))
+	case LESS_LESS:
+		return normalizeInteger(new(big.Int).Lsh(a, i.checkShiftCount(operator, b)))
+	case GREATER_GREATER:
+		return normalizeInteger(new(big.Int).Rsh(a, i.checkShiftCount(operator, b)))
+	}
+	return nil
+}
+
+func (i *Interpreter) complement(operator *Token, operand Any) Any {
+	i.checkIntegerOperand(operator, operand)
+
+	if value, ok := operand.(int64); ok {
+		return ^value
+	}
+	return normalizeInteger(new(big.Int).Not(toBigInt(operand)))
+}
+
+func (i *Interpreter) checkNumberOperand(operator *Token, operand Any) {
+	if isNumber(operand) {
+		return
+	}
+	i.context.runtimeError(operator, "Operand must be a number.")
+}
+
+// checkNumberOperands returns the kind both operands are promoted to.
+func (i *Interpreter) checkNumberOperands(operator *Token, left Any, right Any) numberKind {
+	leftKind, rightKind := kindOfNumber(left), kindOfNumber(right)
+	if leftKind == NUMBER_NONE || rightKind == NUMBER_NONE {
+		i.context.runtimeError(operator, "Operands must be a numbers.")
+	}
+
+	if (leftKind == NUMBER_DECIMAL && rightKind == NUMBER_FLOAT) || (leftKind == NUMBER_FLOAT && rightKind == NUMBER_DECIMAL) {
+		i.context.runtimeError(operator, "Can't mix decimal and float operands.")
+	}
+
+	if leftKind > rightKind {
+		return leftKind
+	}
+	return rightKind
+}
+
+func (i *Interpreter) checkDivisor(operator *Token, divisor Any) {
+	zero := false
+	switch divisor := divisor.(type) {
+	case int64:
+		zero = divisor == 0
+	case *Decimal:
+		zero = divisor.Sign() == 0
+	case float:
+		zero = divisor == 0
+	}
+
+	if zero {
+		i.context.runtimeError(operator, "Division by zero.")
+	}
+}
+
+func (i *Interpreter) checkIntegerOperand(operator *Token, operand Any) {
+	if !isInteger(operand) {
+		i.context.runtimeError(operator, "Operand must be an integer.")
+	}
+}
+
+func (i *Interpreter) checkIntegerOperands(operator *Token, left Any, right Any) {
+	if !isInteger(left) || !isInteger(right) {
+		i.context.runtimeError(operator, "Operands must be integers.")
+	}
+}
+
+func (i *Interpreter) checkShiftCount(operator *Token, count *big.Int) uint {
+	if count.Sign() < 0 {
+		i.context.runtimeError(operator, "Shift count must not be negative.")
+	}
+	if !count.IsInt64() || count.Int64() > 1<<24 {
+		i.context.runtimeError(operator, "Shift count is too large.")
+	}
+	return uint(count.Int64())
+}
@@ -0,0 +1,216 @@
+package main
+
+import "strings"
+
+// operatorMethod names special methods implementing binary operators for
+// the left operand, and their reflected variants called on the right one.
+type operatorMethod struct {
+	name      string
+	reflected string
+}
+
+var operatorMethods = map[TokenType]operatorMethod{
+	PLUS:            {"__add__", "__radd__"},
+	MINUS:           {"__sub__", "__rsub__"},
+	STAR:            {"__mul__", "__rmul__"},
+	SLASH:           {"__div__", "__rdiv__"},
+	TILDE_SLASH:     {"__floordiv__", "__rfloordiv__"},
+	PERCENT:         {"__mod__", "__rmod__"},
+	STAR_STAR:       {"__pow__", "__rpow__"},
+	AMPERSAND:       {"__and__", "__rand__"},
+	PIPE:            {"__or__", "__ror__"},
+	CARET:           {"__xor__", "__rxor__"},
+	LESS_LESS:       {"__lshift__", "__rlshift__"},
+	GREATER_GREATER: {"__rshift__", "__rrshift__"},
+
+	// Comparisons are reflected by swapping the operands.
+	LESS:          {"__lt__", "__gt__"},
+	LESS_EQUAL:    {"__le__", "__ge__"},
+	GREATER:       {"__gt__", "__lt__"},
+	GREATER_EQUAL: {"__ge__", "__le__"},
+}
+
+var unaryMethods = map[TokenType]string{
+	MINUS: "__neg__",
+	TILDE: "__invert__",
+}
+
+// findSpecial returns special method of value bound to it, or nil when
+// value isn't instance or doesn't have the method.
+func findSpecial(value Any, name string) *LoxFunction {
+	instance, ok := value.(*LoxInstance)
+	if !ok {
+		return nil
+	}
+	if method := instance.klass.findMethod(name); method != nil {
+		return method.bind(instance)
+	}
+	return nil
+}
+
+// callSpecial calls special method checking number of its arguments, which
+// is reported at token, or at the method declaration when token is nil.
+func (i *Interpreter) callSpecial(token *Token, method *LoxFunction, arguments ...Any) Any {
+	if token == nil {
+		token = method.declaration.name
+	}
+	if !method.Arity().accepts(len(arguments)) {
+		i.context.runtimeError(token, "Method '%s' must accept %v arguments.", method.declaration.name.lexme, len(arguments))
+	}
+	return method.Call(i, arguments)
+}
+
+// overloaded applies binary operator implemented by special methods of
+// instance operands, it reports whether any of them implements it.
+func (i *Interpreter) overloaded(operator *Token, left Any, right Any) (Any, bool) {
+	methods, ok := operatorMethods[operator.tokenType]
+	if !ok {
+		return nil, false
+	}
+
+	if method := findSpecial(left, methods.name); method != nil {
+		return i.callSpecial(operator, method, right), true
+	}
+	if method := findSpecial(right, methods.reflected); method != nil {
+		return i.callSpecial(operator, method, left), true
+	}
+
+	if instance, ok := left.(*LoxInstance); ok {
+		i.context.runtimeError(operator, "Operator '%s' isn't supported by '%s' instance, it has no '%s' method.", operator.lexme, instance.klass.name, methods.name)
+	}
+	if instance, ok := right.(*LoxInstance); ok {
+		i.context.runtimeError(operator, "Operator '%s' isn't supported by '%s' instance, it has no '%s' method.", operator.lexme, instance.klass.name, methods.reflected)
+	}
+	return nil, false
+}
+
+// overloadedUnary applies unary operator implemented by special method of
+// instance operand, it reports whether the operand implements it.
+func (i *Interpreter) overloadedUnary(operator *Token, operand Any) (Any, bool) {
+	name, ok := unaryMethods[operator.tokenType]
+	if !ok {
+		return nil, false
+	}
+
+	instance, ok := operand.(*LoxInstance)
+	if !ok {
+		return nil, false
+	}
+	if method := findSpecial(instance, name); method != nil {
+		return i.callSpecial(operator, method), true
+	}
+
+	i.context.runtimeError(operator, "Operator '%s' isn't supported by '%s' instance, it has no '%s' method.", operator.lexme, instance.klass.name, name)
+	return nil, false
+}
+
+// isEqual compares values using "__eq__" method of instance operands,
+// other values are compared by isEqual.
+func (i *Interpreter) isEqual(token *Token, a Any, b Any) bool {
+	if method := findSpecial(a, "__eq__"); method != nil {
+		return isTruthy(i.callSpecial(token, method, b))
+	}
+	if method := findSpecial(b, "__eq__"); method != nil {
+		return isTruthy(i.callSpecial(token, method, a))
+	}
+	return isEqual(a, b)
+}
+
+// stringify converts value to string using "__str__" method of instances,
+// including those inside of collections. Errors of the method are reported
+// at its declaration.
+func (i *Interpreter) stringify(value Any) string {
+	return MakeValuePrinter(i).stringify(value)
+}
+
+// ValuePrinter converts values to text. It keeps track of collections being
+// printed, so that a collection containing itself is printed as "[...]" or
+// "{...}" instead of recursing forever.
+type ValuePrinter struct {
+	// interpreter calls "__str__" methods of instances, it's nil when
+	// printing values without running Lox code.
+	interpreter *Interpreter
+	printing    map[Any]bool
+}
+
+func MakeValuePrinter(interpreter *Interpreter) *ValuePrinter {
+	return &ValuePrinter{interpreter: interpreter, printing: make(map[Any]bool)}
+}
+
+func (p *ValuePrinter) stringify(value Any) string {
+	switch value := value.(type) {
+	case *LoxInstance:
+		if p.interpreter == nil {
+			break
+		}
+		if method := findSpecial(value, "__str__"); method != nil {
+			text, ok := p.interpreter.callSpecial(nil, method).(string)
+			if !ok {
+				p.interpreter.context.runtimeError(method.declaration.name, "Method '__str__' of '%s' instance must return a string.", value.klass.name)
+			}
+			return text
+		}
+	case *LoxList:
+		if p.printing[value] {
+			return "[...]"
+		}
+		p.printing[value] = true
+		defer delete(p.printing, value)
+
+		elements := make([]string, len(value.elements))
+		for index, element := range value.elements {
+			elements[index] = p.repr(element)
+		}
+		return "[" + strings.Join(elements, ", ") + "]"
+	case *LoxMap:
+		if p.printing[value] {
+			return "{...}"
+		}
+		p.printing[value] = true
+		defer delete(p.printing, value)
+
+		entries := make([]string, len(value.order))
+		for index, entry := range value.order {
+			entries[index] = p.repr(entry.key) + ": " + p.repr(entry.value)
+		}
+		return "{" + strings.Join(entries, ", ") + "}"
+	case *LoxEnumMember:
+		if value.values != nil {
+			values := make([]string, len(value.values))
+			for index, element := range value.values {
+				values[index] = p.repr(element)
+			}
+			return value.enum.name + "." + value.name + "(" + strings.Join(values, ", ") + ")"
+		}
+	}
+	return stringify(value)
+}
+
+// repr converts value to text shown inside of collections, where strings
+// are quoted.
+func (p *ValuePrinter) repr(value Any) string {
+	if _, ok := value.(string); ok {
+		return repr(value)
+	}
+	return p.stringify(value)
+}
+
+// callable returns value which is called in place of callee, instances
+// are called through their "__call__" method and enum members create their
+// values.
+func (i *Interpreter) callable(callee Any) Any {
+	if method := findSpecial(callee, "__call__"); method != nil {
+		return method
+	}
+	if member, ok := callee.(*LoxEnumMember); ok {
+		if constructor := member.constructor(); constructor != nil {
+			return constructor
+		}
+	}
+	return callee
+}
+
+func isString(value Any) bool {
+	_, ok := value.(string)
+	return ok
+}
@@ -3,20 +3,50 @@ package main
 import (
 	"errors"
 	"fmt"
+	"strings"
 )
 
 type Parser struct {
 	context *LoxContext
 	tokens  []*Token
 	current int
+
+	// docs holds doc comments keyed by the token following them.
+	docs map[*Token]string
+
+	// yields is set once "yield" is parsed in body of the current function.
+	yields bool
+
+	// guardArrow is index of the "=>" token ending the match guard being
+	// parsed, which doesn't start an arrow function, or -1.
+	guardArrow int
 }
 
 func MakeParser(context *LoxContext, tokens []*Token) *Parser {
-	return &Parser{
+	p := &Parser{
 		context: context,
-		tokens:  tokens,
+		tokens:  make([]*Token, 0, len(tokens)),
 		current: 0,
+		docs:    make(map[*Token]string),
+
+		guardArrow: -1,
+	}
+
+	comments := make([]string, 0)
+	for _, token := range tokens {
+		if token.tokenType == DOC_COMMENT {
+			comments = append(comments, token.literal.(string))
+			continue
+		}
+
+		if len(comments) > 0 {
+			p.docs[token] = strings.Join(comments, "\n")
+			comments = comments[:0]
+		}
+		p.tokens = append(p.tokens, token)
 	}
+
+	return p
 }
 
 func (p *Parser) match(tokenTypes ...TokenType) bool {
@@ -36,6 +66,16 @@ func (p *Parser) check(tokenType TokenType) bool {
 	return p.peek().tokenType == tokenType
 }
 
+// checkAhead reports whether token at given distance from the current one
+// has given type.
+func (p *Parser) checkAhead(distance int, tokenType TokenType) bool {
+	index := p.current + distance
+	if index >= len(p.tokens) {
+		return false
+	}
+	return p.tokens[index].tokenType == tokenType
+}
+
 func (p *Parser) advance() *Token {
 	if !p.isAtEnd() {
 		p.current++
@@ -60,20 +100,31 @@ func (p *Parser) expression() Expr {
 	return p.assignment()
 }
 
-// IDENTIFIER "=" assignment | logic_or ;
+// ( call "." | call "[" expression "]" )? IDENTIFIER
+// ( "=" | "+=" | "-=" | "*=" | "/=" | "%=" ) assignment | conditional ;
 func (p *Parser) assignment() Expr {
-	expr := p.or()
+	expr := p.conditional()
 
-	if p.match(EQUAL) {
+	if p.match(EQUAL, PLUS_EQUAL, MINUS_EQUAL, STAR_EQUAL, SLASH_EQUAL, PERCENT_EQUAL) {
 		equals := p.previous()
+		operator := arithmeticOperator(equals)
 		value := p.assignment()
 
 		switch val := expr.(type) {
 		case *VariableExpr:
 			name := val.name
-			return MakeAssignExpr(name, value)
+			return MakeAssignExpr(name, operator, value)
 		case *GetExpr:
-			return MakeSetExpr(val.object, val.name, value)
+			if val.optional {
+				break
+			}
+			return MakeSetExpr(val.object, val.name, operator, value)
+		case *IndexExpr:
+			return MakeSetIndexExpr(val.object, val.bracket, val.index, operator, value)
+		case *ListExpr, *MapExpr:
+			if operator == nil && isDestructuringTarget(val) {
+				return MakeDestructureExpr(val, equals, value)
+			}
 		}
 
 		p.error(equals, "Invalid assignment target.")
@@ -82,6 +133,83 @@ func (p *Parser) assignment() Expr {
 	return expr
 }
 
+// isDestructuringTarget reports whether list or map literal can be
+// assigned to, that is all of its elements are assignment targets.
+func isDestructuringTarget(expr Expr) bool {
+	switch expr := expr.(type) {
+	case *VariableExpr, *IndexExpr:
+		return true
+	case *GetExpr:
+		return !expr.optional
+	case *ListExpr:
+		for index, element := range expr.elements {
+			if spread, ok := element.(*SpreadExpr); ok {
+				if index != len(expr.elements)-1 || !isDestructuringTarget(spread.expression) {
+					return false
+				}
+			} else if !isDestructuringTarget(element) {
+				return false
+			}
+		}
+		return true
+	case *MapExpr:
+		for _, value := range expr.values {
+			if value == nil || !isDestructuringTarget(value) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+var compoundOperators = map[TokenType]TokenType{
+	PLUS_EQUAL:    PLUS,
+	MINUS_EQUAL:   MINUS,
+	STAR_EQUAL:    STAR,
+	SLASH_EQUAL:   SLASH,
+	PERCENT_EQUAL: PERCENT,
+	PLUS_PLUS:     PLUS,
+	MINUS_MINUS:   MINUS,
+}
+
+// arithmeticOperator returns binary operator applied by compound assignment
+// or increment token, or nil for plain assignment.
+func arithmeticOperator(token *Token) *Token {
+	if tokenType, ok := compoundOperators[token.tokenType]; ok {
+		return MakeToken(tokenType, token.lexme, nil, token.line, token.source)
+	}
+	return nil
+}
+
+// coalesce ( "?" expression ":" conditional )? ;
+func (p *Parser) conditional() Expr {
+	expr := p.coalesce()
+
+	if p.match(QUESTION) {
+		question := p.previous()
+		thenBranch := p.expression()
+		p.consume(COLON, "Expect ':' after then branch of conditional expression.")
+		elseBranch := p.conditional()
+		expr = MakeConditionalExpr(expr, question, thenBranch, elseBranch)
+	}
+
+	return expr
+}
+
+// logic_or ( "??" logic_or )* ;
+func (p *Parser) coalesce() Expr {
+	expr := p.or()
+
+	for p.match(QUESTION_QUESTION) {
+		operator := p.previous()
+		right := p.or()
+		expr = MakeLogicalExpr(expr, operator, right)
+	}
+
+	return expr
+}
+
 // logic_and ( "or" logic_and )* ;
 func (p *Parser) or() Expr {
 	expr := p.and()
@@ -119,10 +247,75 @@ func (p *Parser) equality() Expr {
 	return expr
 }
 
-// term ( ( ">" | ">=" | "<" | "<=" ) term )* ;
+// range ( ( ">" | ">=" | "<" | "<=" | "is" ) range )* ;
 func (p *Parser) comparison() Expr {
+	expr := p.rangeExpression()
+	for p.match(GREATER, GREATER_EQUAL, LESS, LESS_EQUAL, IS) {
+		operator := p.previous()
+		right := p.rangeExpression()
+		expr = MakeBinaryExpr(expr, operator, right)
+	}
+	return expr
+}
+
+// bitwise_or ( ( ".." | "..<" ) bitwise_or ( "step" bitwise_or )? )? ;
+func (p *Parser) rangeExpression() Expr {
+	expr := p.bitwiseOr()
+
+	if p.match(DOT_DOT, DOT_DOT_LESS) {
+		operator := p.previous()
+		end := p.bitwiseOr()
+
+		// "step" is not reserved, it's only recognized after range bounds.
+		var step Expr = nil
+		if p.check(IDENTIFIER) && p.peek().lexme == "step" {
+			p.advance()
+			step = p.bitwiseOr()
+		}
+
+		expr = MakeRangeExpr(expr, operator, end, step)
+	}
+
+	return expr
+}
+
+// bitwise_xor ( "|" bitwise_xor )* ;
+func (p *Parser) bitwiseOr() Expr {
+	expr := p.bitwiseXor()
+	for p.match(PIPE) {
+		operator := p.previous()
+		right := p.bitwiseXor()
+		expr = MakeBinaryExpr(expr, operator, right)
+	}
+	return expr
+}
+
+// bitwise_and ( "^" bitwise_and )* ;
+func (p *Parser) bitwiseXor() Expr {
+	expr := p.bitwiseAnd()
+	for p.match(CARET) {
+		operator := p.previous()
+		right := p.bitwiseAnd()
+		expr = MakeBinaryExpr(expr, operator, right)
+	}
+	return expr
+}
+
+// shift ( "&" shift )* ;
+func (p *Parser) bitwiseAnd() Expr {
+	expr := p.shift()
+	for p.match(AMPERSAND) {
+		operator := p.previous()
+		right := p.shift()
+		expr = MakeBinaryExpr(expr, operator, right)
+	}
+	return expr
+}
+
+// term ( ( "<<" | ">>" ) term )* ;
+func (p *Parser) shift() Expr {
 	expr := p.term()
-	for p.match(GREATER, GREATER_EQUAL, LESS, LESS_EQUAL) {
+	for p.match(LESS_LESS, GREATER_GREATER) {
 		operator := p.previous()
 		right := p.term()
 		expr = MakeBinaryExpr(expr, operator, right)
@@ -141,10 +334,10 @@ func (p *Parser) term() Expr {
 	return expr
 }
 
-// unary ( ( "/" | "*" ) unary )* ;
+// unary ( ( "/" | "*" | "%" | "~/" ) unary )* ;
 func (p *Parser) factor() Expr {
 	expr := p.unary()
-	for p.match(SLASH, STAR) {
+	for p.match(SLASH, STAR, PERCENT, TILDE_SLASH) {
 		operator := p.previous()
 		right := p.unary()
 		expr = MakeBinaryExpr(expr, operator, right)
@@ -152,18 +345,60 @@ func (p *Parser) factor() Expr {
 	return expr
 }
 
-// ( "!" | "-" ) unary | call ;
+// ( "!" | "-" | "~" ) unary | ( "++" | "--" ) unary | power ;
 func (p *Parser) unary() Expr {
-	if p.match(BANG, MINUS) {
+	if p.match(BANG, MINUS, TILDE) {
 		operator := p.previous()
 		right := p.unary()
 		return MakeUnaryExpr(operator, right)
 	}
 
-	return p.call()
+	if p.match(PLUS_PLUS, MINUS_MINUS) {
+		operator := p.previous()
+		target := p.unary()
+		return p.update(target, operator, true)
+	}
+
+	return p.power()
 }
 
-// primary ( "(" arguments? ")" )* ;
+// postfix ( "**" unary )? ;
+func (p *Parser) power() Expr {
+	expr := p.postfix()
+	if p.match(STAR_STAR) {
+		// Right associative, so that 2 ** 3 ** 2 is 2 ** (3 ** 2).
+		operator := p.previous()
+		right := p.unary()
+		expr = MakeBinaryExpr(expr, operator, right)
+	}
+	return expr
+}
+
+// call ( "++" | "--" )? ;
+func (p *Parser) postfix() Expr {
+	expr := p.call()
+	if p.match(PLUS_PLUS, MINUS_MINUS) {
+		return p.update(expr, p.previous(), false)
+	}
+	return expr
+}
+
+func (p *Parser) update(target Expr, operator *Token, prefix bool) Expr {
+	switch val := target.(type) {
+	case *VariableExpr, *IndexExpr:
+		return MakeUpdateExpr(target, arithmeticOperator(operator), prefix)
+	case *GetExpr:
+		if !val.optional {
+			return MakeUpdateExpr(target, arithmeticOperator(operator), prefix)
+		}
+	}
+
+	p.error(operator, "Invalid increment target.")
+	return target
+}
+
+// primary ( "(" arguments? ")" | ( "." | "?." ) IDENTIFIER
+// | "[" expression "]" | "[" expression? ":" expression? ( ":" expression? )? "]" )* ;
 func (p *Parser) call() Expr {
 	expr := p.primary()
 
@@ -172,7 +407,12 @@ func (p *Parser) call() Expr {
 			expr = p.finishCall(expr)
 		} else if p.match(DOT) {
 			name := p.consume(IDENTIFIER, "Expect property name after '.'.")
-			expr = MakeGetExpr(expr, name)
+			expr = MakeGetExpr(expr, name, false)
+		} else if p.match(QUESTION_DOT) {
+			name := p.consume(IDENTIFIER, "Expect property name after '?.'.")
+			expr = MakeGetExpr(expr, name, true)
+		} else if p.match(LEFT_BRACKET) {
+			expr = p.index(expr)
 		} else {
 			break
 		}
@@ -181,15 +421,54 @@ func (p *Parser) call() Expr {
 	return expr
 }
 
+func (p *Parser) index(object Expr) Expr {
+	var index Expr = nil
+	if !p.check(COLON) {
+		index = p.expression()
+	}
+
+	if !p.match(COLON) {
+		bracket := p.consume(RIGHT_BRACKET, "Expect ']' after index.")
+		return MakeIndexExpr(object, bracket, index)
+	}
+
+	var end, step Expr = nil, nil
+	if !p.check(COLON) && !p.check(RIGHT_BRACKET) {
+		end = p.expression()
+	}
+	if p.match(COLON) && !p.check(RIGHT_BRACKET) {
+		step = p.expression()
+	}
+
+	bracket := p.consume(RIGHT_BRACKET, "Expect ']' after slice.")
+	return MakeSliceExpr(object, bracket, index, end, step)
+}
+
+// ( element | IDENTIFIER ":" expression ) ( "," ... )* ;
 func (p *Parser) finishCall(callee Expr) Expr {
 	arguments := make([]Expr, 0)
+	names := make([]*Token, 0)
 
 	if !p.check(RIGHT_PAREN) {
 		for {
 			if len(arguments) >= 255 {
 				p.error(p.peek(), "Can't have more than 255 arguments.")
 			}
-			arguments = append(arguments, p.expression())
+
+			var name *Token = nil
+			if p.check(IDENTIFIER) && p.checkAhead(1, COLON) {
+				name = p.advance()
+				p.advance()
+			} else if len(names) > 0 && names[len(names)-1] != nil {
+				p.error(p.peek(), "Positional argument can't follow named arguments.")
+			}
+
+			if name != nil {
+				arguments = append(arguments, p.expression())
+			} else {
+				arguments = append(arguments, p.element())
+			}
+			names = append(names, name)
 			if !p.match(COMMA) {
 				break
 			}
@@ -198,10 +477,10 @@ func (p *Parser) finishCall(callee Expr) Expr {
 
 	paren := p.consume(RIGHT_PAREN, "Expect ')' after arguments.")
 
-	return MakeCallExpr(callee, paren, arguments)
+	return MakeCallExpr(callee, paren, arguments, names)
 }
 
-// NUMBER | STRING | "true" | "false" | "nil" | "(" expression ")" ;
+// NUMBER | STRING | interpolation | "true" | "false" | "nil" | "(" expression ")" | list | map ;
 func (p *Parser) primary() Expr {
 	if p.match(TRUE) {
 		return MakeLiteralExpr(true)
@@ -221,23 +500,43 @@ func (p *Parser) primary() Expr {
 	}
 
 	if p.match(FUN) {
-		return p.function("function")
+		return p.function("function", "")
 	}
 
 	if p.match(THIS) {
 		return MakeThisExpr(p.previous())
 	}
 
+	if p.closesInterpolation() {
+		panic(p.error(p.peek(), "Expected expression."))
+	}
+
 	if p.match(NUMBER, STRING) {
 		return MakeLiteralExpr(p.previous().literal)
 	}
 
+	if p.match(INTERPOLATION) {
+		return p.interpolation()
+	}
+
+	if p.isArrowFunction() {
+		return p.arrowFunction()
+	}
+
 	if p.match(LEFT_PAREN) {
 		expr := p.expression()
 		p.consume(RIGHT_PAREN, "Expect ')' after expression.")
 		return MakeGroupingExpr(expr)
 	}
 
+	if p.match(LEFT_BRACKET) {
+		return p.list()
+	}
+
+	if p.match(LEFT_BRACE) {
+		return p.mapping()
+	}
+
 	if p.match(IDENTIFIER) {
 		return MakeVariableExpr(p.previous())
 	}
@@ -245,6 +544,152 @@ func (p *Parser) primary() Expr {
 	panic(p.error(p.peek(), "Expected expression."))
 }
 
+// isArrowFunction reports whether an arrow function starts at the current
+// token, that is a parameter name or parenthesized parameter list followed by
+// "=>".
+func (p *Parser) isArrowFunction() bool {
+	if p.check(IDENTIFIER) {
+		return p.isArrow(p.current + 1)
+	}
+	if !p.check(LEFT_PAREN) {
+		return false
+	}
+
+	depth := 0
+	for index := p.current; index < len(p.tokens); index++ {
+		switch p.tokens[index].tokenType {
+		case LEFT_PAREN:
+			depth++
+		case RIGHT_PAREN:
+			depth--
+			if depth == 0 {
+				return p.isArrow(index + 1)
+			}
+		case EOF:
+			return false
+		}
+	}
+	return false
+}
+
+func (p *Parser) isArrow(index int) bool {
+	return index < len(p.tokens) && index != p.guardArrow && p.tokens[index].tokenType == ARROW
+}
+
+// arrowFunction → ( IDENTIFIER | "(" parameters? ")" ) "=>" ( block | expression ) ;
+func (p *Parser) arrowFunction() Expr {
+	var paren *Token
+	var parameters []*Token
+	var defaults []Expr
+	var rest *Token = nil
+
+	if p.match(IDENTIFIER) {
+		paren = p.previous()
+		parameters = []*Token{paren}
+		defaults = []Expr{nil}
+	} else {
+		paren = p.consume(LEFT_PAREN, "Expect '(' before parameters.")
+		parameters, defaults, rest = p.parameters()
+	}
+
+	arrow := p.consume(ARROW, "Expect '=>' after parameters.")
+
+	enclosingYields, enclosingGuard := p.yields, p.guardArrow
+	p.yields, p.guardArrow = false, -1
+	defer func() {
+		p.yields, p.guardArrow = enclosingYields, enclosingGuard
+	}()
+
+	if p.match(LEFT_BRACE) {
+		body := p.block()
+		return MakeFunctionExpr(nil, paren, parameters, defaults, rest, body, "", p.yields)
+	}
+
+	// Expression bodies return value of the expression.
+	body := []Stmt{MakeReturnStmt(arrow, p.expression())}
+	return MakeFunctionExpr(nil, paren, parameters, defaults, rest, body, "", false)
+}
+
+// "[" ( element ( "," element )* ","? )? "]" ;
+func (p *Parser) list() Expr {
+	bracket := p.previous()
+	elements := make([]Expr, 0)
+
+	for !p.check(RIGHT_BRACKET) {
+		elements = append(elements, p.element())
+		if !p.match(COMMA) {
+			break
+		}
+	}
+
+	p.consume(RIGHT_BRACKET, "Expect ']' after list elements.")
+	return MakeListExpr(bracket, elements)
+}
+
+// "{" ( entry ( "," entry )* ","? )? "}" ;
+// entry → expression ":" expression | IDENTIFIER | "..." expression ;
+func (p *Parser) mapping() Expr {
+	brace := p.previous()
+	keys := make([]Expr, 0)
+	values := make([]Expr, 0)
+
+	for !p.check(RIGHT_BRACE) {
+		if p.match(DOT_DOT_DOT) {
+			// Spread entries have no value, all entries of the spread map
+			// are copied.
+			keys = append(keys, MakeSpreadExpr(p.previous(), p.expression()))
+			values = append(values, nil)
+		} else if p.check(IDENTIFIER) && (p.checkAhead(1, COMMA) || p.checkAhead(1, RIGHT_BRACE)) {
+			// {x} is a shorthand for {"x": x}.
+			name := p.advance()
+			keys = append(keys, MakeLiteralExpr(name.lexme))
+			values = append(values, MakeVariableExpr(name))
+		} else {
+			keys = append(keys, p.expression())
+			p.consume(COLON, "Expect ':' after map key.")
+			values = append(values, p.expression())
+		}
+		if !p.match(COMMA) {
+			break
+		}
+	}
+
+	p.consume(RIGHT_BRACE, "Expect '}' after map entries.")
+	return MakeMapExpr(brace, keys, values)
+}
+
+// "..." expression | expression ;
+func (p *Parser) element() Expr {
+	if p.match(DOT_DOT_DOT) {
+		return MakeSpreadExpr(p.previous(), p.expression())
+	}
+	return p.expression()
+}
+
+// INTERPOLATION expression ( INTERPOLATION expression )* STRING ;
+func (p *Parser) interpolation() Expr {
+	parts := []Expr{MakeLiteralExpr(p.previous().literal)}
+
+	for {
+		parts = append(parts, p.expression())
+		if !p.match(INTERPOLATION) {
+			break
+		}
+		parts = append(parts, MakeLiteralExpr(p.previous().literal))
+	}
+
+	end := p.consume(STRING, "Expect end of string interpolation.")
+	parts = append(parts, MakeLiteralExpr(end.literal))
+	return MakeInterpolationExpr(parts)
+}
+
+// closesInterpolation reports whether the current token is the rest of a
+// string following an interpolated expression, such token can't start
+// another expression.
+func (p *Parser) closesInterpolation() bool {
+	return (p.check(STRING) || p.check(INTERPOLATION)) && strings.HasPrefix(p.peek().lexme, "}")
+}
+
 func (p *Parser) consume(tokenType TokenType, message string, a ...interface{}) *Token {
 	if p.check(tokenType) {
 		return p.advance()
@@ -290,14 +735,26 @@ func (p *Parser) program() []Stmt {
 	return statements
 }
 
+// docComment returns doc comment written right before the current token.
+func (p *Parser) docComment() string {
+	return p.docs[p.peek()]
+}
+
 func (p *Parser) declaration() (result Stmt) {
 	if tryCatch(func() {
-		if p.match(VAR) {
-			result = p.varDeclaration()
+		doc := p.docComment()
+		if p.match(VAR, CONST, LET) {
+			result = p.varDeclaration(doc)
 		} else if p.match(CLASS) {
-			result = p.classDeclaration()
+			result = p.classDeclaration(doc)
+		} else if p.matchContextual("trait") {
+			result = p.traitDeclaration(doc)
+		} else if p.matchContextual("interface") {
+			result = p.interfaceDeclaration(doc)
+		} else if p.matchContextual("enum") {
+			result = p.enumDeclaration(doc)
 		} else if p.match(FUN) {
-			function := p.function("function")
+			function := p.function("function", doc)
 			result = MakeExpressionStmt(function)
 		} else {
 			result = p.statement()
@@ -309,7 +766,7 @@ func (p *Parser) declaration() (result Stmt) {
 	return
 }
 
-func (p *Parser) classDeclaration() Stmt {
+func (p *Parser) classDeclaration(doc string) Stmt {
 	name := p.consume(IDENTIFIER, "Expect class name.")
 
 	var superclass *VariableExpr = nil
@@ -318,26 +775,230 @@ func (p *Parser) classDeclaration() Stmt {
 		superclass = MakeVariableExpr(p.previous())
 	}
 
+	traits := p.names("with", "trait")
+	interfaces := p.names("implements", "interface")
+
 	p.consume(LEFT_BRACE, "Expect '{' after class name.")
 
 	methods := make([]*FunctionExpr, 0)
+	abstractMethods := make([]*FunctionExpr, 0)
+	staticMethods := make([]*FunctionExpr, 0)
+	getters := make([]*FunctionExpr, 0)
+	setters := make([]*FunctionExpr, 0)
+	fields := make([]*VarStmt, 0)
+	staticFields := make([]*VarStmt, 0)
+
 	for !p.check(RIGHT_BRACE) && !p.isAtEnd() {
-		methods = append(methods, p.function("method"))
+		doc := p.docComment()
+		static := p.matchContextual("static")
+		if static && isPrivate(p.peek()) {
+			p.error(p.peek(), "Static members can't be private.")
+		}
+
+		if p.matchContextual("abstract") {
+			abstract := p.previous()
+			if static {
+				p.error(abstract, "Abstract methods can't be static.")
+			}
+
+			method := p.signature("method", doc)
+			if method.name.lexme == "init" || isPrivate(method.name) {
+				p.error(method.name, "Initializers and private methods can't be abstract.")
+			}
+			abstractMethods = append(abstractMethods, method)
+		} else if accessor := p.matchAccessor(); accessor != nil {
+			if static {
+				p.error(accessor, "Accessors can't be static.")
+			}
+
+			if accessor.lexme == "get" {
+				getter := p.function("getter", doc)
+				if len(getter.params) > 0 || getter.rest != nil {
+					p.error(getter.paren, "Getter can't have parameters.")
+				}
+				getters = append(getters, getter)
+			} else {
+				setter := p.function("setter", doc)
+				if len(setter.params) != 1 || setter.rest != nil {
+					p.error(setter.paren, "Setter must have exactly one parameter.")
+				}
+				setters = append(setters, setter)
+			}
+		} else if p.check(IDENTIFIER) && (p.checkAhead(1, EQUAL) || p.checkAhead(1, SEMICOLON)) {
+			if static {
+				staticFields = append(staticFields, p.field(doc))
+			} else {
+				fields = append(fields, p.field(doc))
+			}
+		} else if static {
+			staticMethods = append(staticMethods, p.function("method", doc))
+		} else {
+			methods = append(methods, p.function("method", doc))
+		}
 	}
 
 	p.consume(RIGHT_BRACE, "Expect '}' after class body.")
 
-	return MakeClassStmt(name, superclass, methods)
+	return MakeClassStmt(name, superclass, traits, interfaces, methods, abstractMethods, staticMethods, getters, setters, fields, staticFields, doc)
+}
+
+// "trait" IDENTIFIER "{" function* "}" ;
+func (p *Parser) traitDeclaration(doc string) Stmt {
+	name := p.consume(IDENTIFIER, "Expect trait name.")
+	p.consume(LEFT_BRACE, "Expect '{' after trait name.")
+
+	methods := make([]*FunctionExpr, 0)
+	for !p.check(RIGHT_BRACE) && !p.isAtEnd() {
+		method := p.function("method", p.docComment())
+		if method.name != nil && (method.name.lexme == "init" || isPrivate(method.name)) {
+			p.error(method.name, "Traits can't declare initializers or private methods.")
+		}
+		methods = append(methods, method)
+	}
+
+	p.consume(RIGHT_BRACE, "Expect '}' after trait body.")
+	return MakeTraitStmt(name, methods, doc)
 }
 
-func (p *Parser) function(kind string) *FunctionExpr {
+// "interface" IDENTIFIER "{" signature* "}" ;
+func (p *Parser) interfaceDeclaration(doc string) Stmt {
+	name := p.consume(IDENTIFIER, "Expect interface name.")
+	p.consume(LEFT_BRACE, "Expect '{' after interface name.")
+
+	methods := make([]*FunctionExpr, 0)
+	for !p.check(RIGHT_BRACE) && !p.isAtEnd() {
+		method := p.signature("method", p.docComment())
+		if method.name.lexme == "init" || isPrivate(method.name) {
+			p.error(method.name, "Interfaces can't declare initializers or private methods.")
+		}
+		methods = append(methods, method)
+	}
+
+	p.consume(RIGHT_BRACE, "Expect '}' after interface body.")
+	return MakeInterfaceStmt(name, methods, doc)
+}
+
+// "enum" IDENTIFIER "{" ( member ( "," member )* ","? )? "}" ;
+// member → IDENTIFIER ( "(" IDENTIFIER ( "," IDENTIFIER )* ")" )? ;
+func (p *Parser) enumDeclaration(doc string) Stmt {
+	name := p.consume(IDENTIFIER, "Expect enum name.")
+	p.consume(LEFT_BRACE, "Expect '{' after enum name.")
+
+	members := make([]*Token, 0)
+	params := make([][]*Token, 0)
+	docs := make([]string, 0)
+	for !p.check(RIGHT_BRACE) && !p.isAtEnd() {
+		docs = append(docs, p.docComment())
+		members = append(members, p.consume(IDENTIFIER, "Expect member name."))
+
+		values := make([]*Token, 0)
+		if p.match(LEFT_PAREN) {
+			for {
+				values = append(values, p.consume(IDENTIFIER, "Expect associated value name."))
+				if !p.match(COMMA) {
+					break
+				}
+			}
+			p.consume(RIGHT_PAREN, "Expect ')' after associated values.")
+		}
+		params = append(params, values)
+
+		if !p.match(COMMA) {
+			break
+		}
+	}
+
+	p.consume(RIGHT_BRACE, "Expect '}' after enum members.")
+	return MakeEnumStmt(name, members, params, docs, doc)
+}
+
+// names parses comma separated names of kind following contextual keyword,
+// e.g. traits after "with". It returns empty list when there's no keyword.
+func (p *Parser) names(keyword string, kind string) []*VariableExpr {
+	names := make([]*VariableExpr, 0)
+	if p.check(IDENTIFIER) && p.peek().lexme == keyword {
+		p.advance()
+		for {
+			names = append(names, MakeVariableExpr(p.consume(IDENTIFIER, "Expect %s name.", kind)))
+			if !p.match(COMMA) {
+				break
+			}
+		}
+	}
+	return names
+}
+
+// matchContextual consumes contextual keyword, such as "static" or "trait",
+// which is an identifier followed by another identifier. Otherwise it's still
+// a valid name, e.g. of a method.
+func (p *Parser) matchContextual(keyword string) bool {
+	if p.check(IDENTIFIER) && p.peek().lexme == keyword && p.checkAhead(1, IDENTIFIER) {
+		p.advance()
+		return true
+	}
+	return false
+}
+
+// matchAccessor consumes contextual "get" or "set" keyword preceding name of
+// accessor and returns it, or returns nil for other members.
+func (p *Parser) matchAccessor() *Token {
+	if p.check(IDENTIFIER) && (p.peek().lexme == "get" || p.peek().lexme == "set") && p.checkAhead(1, IDENTIFIER) {
+		return p.advance()
+	}
+	return nil
+}
+
+// field → IDENTIFIER ( "=" expression )? ";" ;
+func (p *Parser) field(doc string) *VarStmt {
+	name := p.consume(IDENTIFIER, "Expect field name.")
+
+	var initializer Expr = nil
+	if p.match(EQUAL) {
+		initializer = p.expression()
+	}
+
+	p.consume(SEMICOLON, "Expect ';' after field declaration.")
+	return MakeVarStmt(name, initializer, doc, false)
+}
+
+func (p *Parser) function(kind string, doc string) *FunctionExpr {
 	var identifier *Token
 	if p.match(IDENTIFIER) {
 		identifier = p.previous()
 	}
 
 	paren := p.consume(LEFT_PAREN, "Expect '(' after %s name.", kind)
+	parameters, defaults, rest := p.parameters()
+
+	p.consume(LEFT_BRACE, "Expect '{' before %s body.", kind)
+
+	enclosingYields := p.yields
+	p.yields = false
+	body := p.block()
+	generator := p.yields
+	p.yields = enclosingYields
+
+	return MakeFunctionExpr(identifier, paren, parameters, defaults, rest, body, doc, generator)
+}
+
+// signature → IDENTIFIER "(" parameters? ")" ";" ;
+//
+// Signature declares method without body, the body of returned function is
+// nil.
+func (p *Parser) signature(kind string, doc string) *FunctionExpr {
+	identifier := p.consume(IDENTIFIER, "Expect %s name.", kind)
+	paren := p.consume(LEFT_PAREN, "Expect '(' after %s name.", kind)
+	parameters, defaults, rest := p.parameters()
+	p.consume(SEMICOLON, "Expect ';' after %s signature.", kind)
+
+	return MakeFunctionExpr(identifier, paren, parameters, defaults, rest, nil, doc, false)
+}
+
+// parameters parses parameter list following "(" up to and including ")".
+func (p *Parser) parameters() ([]*Token, []Expr, *Token) {
 	parameters := make([]*Token, 0)
+	defaults := make([]Expr, 0)
+	var rest *Token = nil
 
 	if !p.check(RIGHT_PAREN) {
 		for {
@@ -345,7 +1006,25 @@ func (p *Parser) function(kind string) *FunctionExpr {
 				p.error(p.peek(), "Can't have more than 255 parameters.")
 			}
 
-			parameters = append(parameters, p.consume(IDENTIFIER, "Expect parameter name."))
+			if p.match(DOT_DOT_DOT) {
+				rest = p.consume(IDENTIFIER, "Expect rest parameter name.")
+				if !p.check(RIGHT_PAREN) {
+					p.error(rest, "Rest parameter must be the last parameter.")
+				}
+				break
+			}
+
+			parameter := p.consume(IDENTIFIER, "Expect parameter name.")
+
+			var value Expr = nil
+			if p.match(EQUAL) {
+				value = p.expression()
+			} else if len(defaults) > 0 && defaults[len(defaults)-1] != nil {
+				p.error(parameter, "Parameter without default value can't follow parameters with default values.")
+			}
+
+			parameters = append(parameters, parameter)
+			defaults = append(defaults, value)
 
 			if !p.match(COMMA) {
 				break
@@ -354,22 +1033,37 @@ func (p *Parser) function(kind string) *FunctionExpr {
 	}
 
 	p.consume(RIGHT_PAREN, "Expect ')' after parameters.")
-
-	p.consume(LEFT_BRACE, "Expect '{' before %s body.", kind)
-	body := p.block()
-	return MakeFunctionExpr(identifier, paren, parameters, body)
+	return parameters, defaults, rest
 }
 
-func (p *Parser) varDeclaration() Stmt {
+func (p *Parser) varDeclaration(doc string) Stmt {
+	if p.check(LEFT_BRACKET) || p.check(LEFT_BRACE) {
+		return p.destructuringDeclaration()
+	}
+
+	constant := p.previous().tokenType != VAR
 	name := p.consume(IDENTIFIER, "Expect variable name.")
 
 	var initializer Expr = nil
 	if p.match(EQUAL) {
 		initializer = p.expression()
+	} else if constant {
+		p.error(name, "Constant must be initialized.")
 	}
 
 	p.consume(SEMICOLON, "Expect ';' after variable declaration.")
-	return MakeVarStmt(name, initializer)
+	return MakeVarStmt(name, initializer, doc, constant)
+}
+
+// ( "var" | "const" | "let" ) ( "[" patterns? "]" | "{" entries? "}" ) "=" expression ";" ;
+func (p *Parser) destructuringDeclaration() Stmt {
+	keyword := p.previous()
+	pattern := p.pattern()
+	p.consume(EQUAL, "Expect '=' after destructuring pattern.")
+	initializer := p.expression()
+
+	p.consume(SEMICOLON, "Expect ';' after variable declaration.")
+	return MakeDestructureStmt(keyword, pattern, initializer)
 }
 
 func (p *Parser) statement() Stmt {
@@ -381,6 +1075,10 @@ func (p *Parser) statement() Stmt {
 		return p.returnStatement()
 	}
 
+	if p.match(YIELD) {
+		return p.yieldStatement()
+	}
+
 	if p.match(WHILE) {
 		return p.whileStatement()
 	}
@@ -409,9 +1107,176 @@ func (p *Parser) statement() Stmt {
 		return p.includeStatement()
 	}
 
+	if p.match(MATCH) {
+		return p.matchStatement()
+	}
+
 	return p.expressionStatement()
 }
 
+// "match" "(" expression ")" "{" ( "case" patterns ( "if" expression )? "=>" statement )* "}" ;
+func (p *Parser) matchStatement() Stmt {
+	keyword := p.previous()
+	p.consume(LEFT_PAREN, "Expect '(' after 'match'.")
+	subject := p.expression()
+	p.consume(RIGHT_PAREN, "Expect ')' after match subject.")
+	p.consume(LEFT_BRACE, "Expect '{' before match cases.")
+
+	cases := make([]*Token, 0)
+	patterns := make([][]Expr, 0)
+	guards := make([]Expr, 0)
+	bodies := make([]Stmt, 0)
+
+	for !p.check(RIGHT_BRACE) && !p.isAtEnd() {
+		cases = append(cases, p.consume(CASE, "Expect 'case' in match body."))
+
+		alternatives := []Expr{p.pattern()}
+		for p.match(COMMA) {
+			alternatives = append(alternatives, p.pattern())
+		}
+		patterns = append(patterns, alternatives)
+
+		var guard Expr = nil
+		if p.match(IF) {
+			// The "=>" following the guard isn't part of it, even where it
+			// could start an arrow function, e.g. "if (ready) =>".
+			enclosingGuard := p.guardArrow
+			p.guardArrow = p.guardEnd()
+			guard = p.expression()
+			p.guardArrow = enclosingGuard
+		}
+		guards = append(guards, guard)
+
+		p.consume(ARROW, "Expect '=>' after case pattern.")
+		bodies = append(bodies, p.statement())
+	}
+
+	p.consume(RIGHT_BRACE, "Expect '}' after match cases.")
+	return MakeMatchStmt(keyword, subject, cases, patterns, guards, bodies)
+}
+
+// guardEnd returns index of the first "=>" token outside of brackets, which
+// ends the match guard starting at the current token.
+func (p *Parser) guardEnd() int {
+	depth := 0
+	for index := p.current; index < len(p.tokens); index++ {
+		switch p.tokens[index].tokenType {
+		case LEFT_PAREN, LEFT_BRACKET, LEFT_BRACE:
+			depth++
+		case RIGHT_PAREN, RIGHT_BRACKET, RIGHT_BRACE:
+			depth--
+		case ARROW:
+			if depth == 0 {
+				return index
+			}
+		}
+	}
+	return -1
+}
+
+// Patterns reuse expression nodes: VariableExpr binds a name ("_" binds
+// nothing), CallExpr matches class instances, ListExpr and MapExpr match
+// collections and anything else is a constant compared for equality.
+//
+// "_" | IDENTIFIER | literal | "-" NUMBER | IDENTIFIER ( "." IDENTIFIER )+
+// | IDENTIFIER ( "." IDENTIFIER )* "(" patterns? ")"
+// | "[" patterns? ( "," "..." IDENTIFIER )? "]"
+// | "{" ( ( expression ":" pattern | IDENTIFIER ) ( "," ... )* )? "}" ;
+func (p *Parser) pattern() Expr {
+	if p.match(TRUE) {
+		return MakeLiteralExpr(true)
+	}
+	if p.match(FALSE) {
+		return MakeLiteralExpr(false)
+	}
+	if p.match(NIL) {
+		return MakeLiteralExpr(nil)
+	}
+
+	if p.match(NUMBER, STRING) {
+		return MakeLiteralExpr(p.previous().literal)
+	}
+
+	if p.match(MINUS) {
+		operator := p.previous()
+		number := p.consume(NUMBER, "Expect number after '-' in pattern.")
+		return MakeUnaryExpr(operator, MakeLiteralExpr(number.literal))
+	}
+
+	if p.match(LEFT_BRACKET) {
+		bracket := p.previous()
+		elements := make([]Expr, 0)
+		for !p.check(RIGHT_BRACKET) {
+			if p.match(DOT_DOT_DOT) {
+				ellipsis := p.previous()
+				name := p.consume(IDENTIFIER, "Expect rest element name.")
+				elements = append(elements, MakeSpreadExpr(ellipsis, MakeVariableExpr(name)))
+				if !p.check(RIGHT_BRACKET) {
+					p.error(name, "Rest element must be the last one.")
+				}
+				break
+			}
+
+			elements = append(elements, p.pattern())
+			if !p.match(COMMA) {
+				break
+			}
+		}
+		p.consume(RIGHT_BRACKET, "Expect ']' after list pattern.")
+		return MakeListExpr(bracket, elements)
+	}
+
+	if p.match(LEFT_BRACE) {
+		brace := p.previous()
+		keys := make([]Expr, 0)
+		values := make([]Expr, 0)
+		for !p.check(RIGHT_BRACE) {
+			if p.check(IDENTIFIER) && (p.checkAhead(1, COMMA) || p.checkAhead(1, RIGHT_BRACE)) {
+				name := p.advance()
+				keys = append(keys, MakeLiteralExpr(name.lexme))
+				values = append(values, MakeVariableExpr(name))
+				if !p.match(COMMA) {
+					break
+				}
+				continue
+			}
+
+			keys = append(keys, p.expression())
+			p.consume(COLON, "Expect ':' after map pattern key.")
+			values = append(values, p.pattern())
+			if !p.match(COMMA) {
+				break
+			}
+		}
+		p.consume(RIGHT_BRACE, "Expect '}' after map pattern.")
+		return MakeMapExpr(brace, keys, values)
+	}
+
+	if p.match(IDENTIFIER) {
+		var expr Expr = MakeVariableExpr(p.previous())
+		for p.match(DOT) {
+			name := p.consume(IDENTIFIER, "Expect property name after '.'.")
+			expr = MakeGetExpr(expr, name, false)
+		}
+
+		if p.match(LEFT_PAREN) {
+			arguments := make([]Expr, 0)
+			for !p.check(RIGHT_PAREN) {
+				arguments = append(arguments, p.pattern())
+				if !p.match(COMMA) {
+					break
+				}
+			}
+			paren := p.consume(RIGHT_PAREN, "Expect ')' after instance pattern.")
+			return MakeCallExpr(expr, paren, arguments, make([]*Token, len(arguments)))
+		}
+
+		return expr
+	}
+
+	panic(p.error(p.peek(), "Expect pattern."))
+}
+
 func (p *Parser) includeStatement() Stmt {
 	keyword := p.previous()
 	path := p.consume(STRING, "Expect file name.")
@@ -443,15 +1308,40 @@ func (p *Parser) returnStatement() Stmt {
 	return MakeReturnStmt(keyword, value)
 }
 
-// "for" "(" ( varDecl | exprStmt | ";" ) expression? ";" expression? ")" statement ;
+// "yield" expression? ";" ;
+func (p *Parser) yieldStatement() Stmt {
+	keyword := p.previous()
+	var value Expr = nil
+	if !p.check(SEMICOLON) {
+		value = p.expression()
+	}
+
+	p.yields = true
+	p.consume(SEMICOLON, "Expect ';' after yield value.")
+	return MakeYieldStmt(keyword, value)
+}
+
+// "for" "(" ( varDecl | exprStmt | ";" ) expression? ";" expression? ")" statement
+// | "for" "(" "var" IDENTIFIER "in" expression ")" statement ;
 func (p *Parser) forStatement() Stmt {
 	p.consume(LEFT_PAREN, "Expect '(' after 'for'.")
 
+	if p.check(VAR) && p.checkAhead(2, IN) {
+		p.advance()
+		name := p.consume(IDENTIFIER, "Expect variable name.")
+		keyword := p.advance()
+		iterable := p.expression()
+		p.consume(RIGHT_PAREN, "Expect ')' after for-in clause.")
+		body := p.statement()
+
+		return MakeForInStmt(name, keyword, iterable, body)
+	}
+
 	var initializer Stmt
 	if p.match(SEMICOLON) {
 		initializer = nil
 	} else if p.match(VAR) {
-		initializer = p.varDeclaration()
+		initializer = p.varDeclaration("")
 	} else {
 		initializer = p.expressionStatement()
 	}
@@ -0,0 +1,400 @@
+package main
+
+// patternBindings returns names of variables bound by pattern.
+func patternBindings(pattern Expr) []*Token {
+	bindings := make([]*Token, 0)
+
+	switch pattern := pattern.(type) {
+	case *VariableExpr:
+		if pattern.name.lexme != "_" {
+			bindings = append(bindings, pattern.name)
+		}
+		break
+	case *ListExpr:
+		for _, element := range pattern.elements {
+			bindings = append(bindings, patternBindings(element)...)
+		}
+		break
+	case *MapExpr:
+		for _, value := range pattern.values {
+			bindings = append(bindings, patternBindings(value)...)
+		}
+		break
+	case *CallExpr:
+		for _, argument := range pattern.arguments {
+			bindings = append(bindings, patternBindings(argument)...)
+		}
+		break
+	case *SpreadExpr:
+		bindings = append(bindings, patternBindings(pattern.expression)...)
+		break
+	}
+
+	return bindings
+}
+
+// resolvePattern resolves expressions evaluated while matching pattern,
+// such as constants, map keys and class names.
+func (r *Resolver) resolvePattern(pattern Expr) {
+	switch pattern := pattern.(type) {
+	case *VariableExpr, *SpreadExpr:
+		break
+	case *ListExpr:
+		for _, element := range pattern.elements {
+			r.resolvePattern(element)
+		}
+		break
+	case *MapExpr:
+		for index, key := range pattern.keys {
+			r.resolveExpr(key)
+			r.resolvePattern(pattern.values[index])
+		}
+		break
+	case *CallExpr:
+		r.resolveExpr(pattern.callee)
+		for _, argument := range pattern.arguments {
+			r.resolvePattern(argument)
+		}
+		break
+	default:
+		r.resolveExpr(pattern)
+		break
+	}
+}
+
+func (r *Resolver) visitMatchStmt(stmt *MatchStmt) Any {
+	r.resolveExpr(stmt.subject)
+
+	// Cases are unreachable when they follow an unguarded catch-all case,
+	// or when all of their patterns are literals matched by earlier cases.
+	catchAll := false
+	literals := make(map[Any]bool)
+
+	for index, alternatives := range stmt.patterns {
+		guard := stmt.guards[index]
+		reachable := false
+		matched := make([]Any, 0)
+		bindings := make([]*Token, 0)
+
+		for _, pattern := range alternatives {
+			r.resolvePattern(pattern)
+			bindings = append(bindings, patternBindings(pattern)...)
+
+			if literal, ok := pattern.(*LiteralExpr); ok {
+				key := mapKey(literal.value)
+				if !literals[key] {
+					reachable = true
+				}
+				matched = append(matched, key)
+			} else {
+				reachable = true
+			}
+		}
+
+		if catchAll {
+			r.context.tokenError(stmt.cases[index], "Unreachable case, an earlier case matches every value.")
+		} else if !reachable {
+			r.context.tokenError(stmt.cases[index], "Unreachable case, its patterns are matched by earlier cases.")
+		}
+
+		if len(alternatives) > 1 && len(bindings) > 0 {
+			r.context.tokenError(bindings[0], "Can't bind variables in alternative patterns.")
+		}
+
+		if guard == nil {
+			for _, key := range matched {
+				literals[key] = true
+			}
+			for _, pattern := range alternatives {
+				if _, ok := pattern.(*VariableExpr); ok {
+					catchAll = true
+				}
+			}
+		}
+
+		r.beginScope()
+		for _, name := range bindings {
+			r.declare(name)
+			r.define(name)
+		}
+		if guard != nil {
+			r.resolveExpr(guard)
+		}
+		r.resolveStmt(stmt.bodies[index])
+		r.endScope()
+	}
+
+	r.checkExhaustive(stmt)
+	return nil
+}
+
+func (i *Interpreter) visitMatchStmt(stmt *MatchStmt) Any {
+	subject := i.evaluate(stmt.subject)
+
+	for index, alternatives := range stmt.patterns {
+		for _, pattern := range alternatives {
+			environment := i.environment.extend()
+			if !i.matchPattern(pattern, subject, environment) {
+				continue
+			}
+			if guard := stmt.guards[index]; guard != nil && !i.guardMatches(guard, environment) {
+				continue
+			}
+
+			i.executeBlock([]Stmt{stmt.bodies[index]}, environment)
+			return nil
+		}
+	}
+
+	return nil
+}
+
+func (i *Interpreter) guardMatches(guard Expr, environment *Environment) bool {
+	return isTruthy(i.evaluateIn(guard, environment))
+}
+
+// matchPattern reports whether value matches pattern, variables bound by
+// the pattern are defined in environment.
+func (i *Interpreter) matchPattern(pattern Expr, value Any, environment *Environment) bool {
+	switch pattern := pattern.(type) {
+	case *VariableExpr:
+		if pattern.name.lexme != "_" {
+			environment.define(pattern.name.lexme, value)
+		}
+		return true
+
+	case *ListExpr:
+		list, ok := value.(*LoxList)
+		if !ok {
+			return false
+		}
+
+		elements, rest := splitRest(pattern)
+		if len(list.elements) < len(elements) || (rest == nil && len(list.elements) != len(elements)) {
+			return false
+		}
+		for index, element := range elements {
+			if !i.matchPattern(element, list.elements[index], environment) {
+				return false
+			}
+		}
+		if rest != nil {
+			return i.matchPattern(rest.expression, restOf(list, len(elements)), environment)
+		}
+		return true
+
+	case *MapExpr:
+		for index, key := range pattern.keys {
+			entry, ok := entryOf(value, i.evaluate(key))
+			if !ok || !i.matchPattern(pattern.values[index], entry, environment) {
+				return false
+			}
+		}
+		return true
+
+	case *CallExpr:
+		callee := i.evaluate(pattern.callee)
+		if member, ok := callee.(*LoxEnumMember); ok {
+			return i.matchMember(pattern, member, value, environment)
+		}
+		return i.matchInstance(pattern, callee, value, environment)
+	}
+
+	expected := i.evaluate(pattern)
+	if member, ok := expected.(*LoxEnumMember); ok && member.constructor() != nil {
+		return member.isDeclarationOf(value)
+	}
+	return i.isEqual(nil, expected, value)
+}
+
+// matchInstance matches instances of the class named by pattern callee,
+// positional sub-patterns are matched against fields named after the
+// initializer parameters.
+func (i *Interpreter) matchInstance(pattern *CallExpr, callee Any, value Any, environment *Environment) bool {
+	klass, ok := callee.(*LoxClass)
+	if !ok {
+		i.context.runtimeError(pattern.paren, "Only classes and enum members can be used in instance patterns.")
+	}
+
+	instance, ok := value.(*LoxInstance)
+	if !ok || !instance.klass.isSubclassOf(klass) {
+		return false
+	}
+
+	var params []*Token
+	if initializer := klass.findMethod("init"); initializer != nil {
+		params = initializer.declaration.params
+	}
+	if len(pattern.arguments) > len(params) {
+		i.context.runtimeError(pattern.paren, "Expected at most %v patterns for class '%s' but got %v.", len(params), klass.name, len(pattern.arguments))
+	}
+
+	for index, argument := range pattern.arguments {
+		field, ok := instance.fields[params[index].lexme]
+		if !ok || !i.matchPattern(argument, field, environment) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// splitRest splits list pattern into its elements and the trailing rest
+// element, if any.
+func splitRest(pattern *ListExpr) ([]Expr, *SpreadExpr) {
+	count := len(pattern.elements)
+	if count > 0 {
+		if rest, ok := pattern.elements[count-1].(*SpreadExpr); ok {
+			return pattern.elements[:count-1], rest
+		}
+	}
+	return pattern.elements, nil
+}
+
+// restOf returns new list with elements of list following the first count
+// ones.
+func restOf(list *LoxList, count int) *LoxList {
+	elements := make([]Any, len(list.elements)-count)
+	copy(elements, list.elements[count:])
+	return MakeLoxList(elements)
+}
+
+// entryOf returns value of map entry, or of instance field named by key.
+func entryOf(value Any, key Any) (Any, bool) {
+	switch value := value.(type) {
+	case *LoxMap:
+		return value.get(key)
+	case *LoxInstance:
+		if name, ok := key.(string); ok {
+			field, ok := value.fields[name]
+			return field, ok
+		}
+	}
+	return nil, false
+}
+
+func (r *Resolver) visitDestructureStmt(stmt *DestructureStmt) Any {
+	bindings := patternBindings(stmt.pattern)
+	for _, name := range bindings {
+		r.declare(name)
+	}
+
+	r.resolveExpr(stmt.initializer)
+	r.resolvePattern(stmt.pattern)
+
+	for _, name := range bindings {
+		r.define(name)
+		if stmt.keyword.tokenType != VAR {
+			r.defineConstant(name)
+		}
+	}
+	return nil
+}
+
+func (r *Resolver) visitDestructureExpr(expr *DestructureExpr) Any {
+	r.resolveExpr(expr.value)
+	r.resolveTarget(expr.pattern)
+	return nil
+}
+
+// resolveTarget resolves variables and sub-expressions of assignment
+// target.
+func (r *Resolver) resolveTarget(target Expr) {
+	switch target := target.(type) {
+	case *VariableExpr:
+		r.checkAssignable(target.name)
+		r.resolveLocal(target, target.name)
+		break
+	case *SpreadExpr:
+		r.resolveTarget(target.expression)
+		break
+	case *ListExpr:
+		for _, element := range target.elements {
+			r.resolveTarget(element)
+		}
+		break
+	case *MapExpr:
+		for index, key := range target.keys {
+			r.resolveExpr(key)
+			r.resolveTarget(target.values[index])
+		}
+		break
+	default:
+		// Objects (and indices) of property and index targets.
+		r.resolveExpr(target)
+		break
+	}
+}
+
+func (i *Interpreter) visitDestructureStmt(stmt *DestructureStmt) Any {
+	value := i.evaluate(stmt.initializer)
+
+	// Match into a new environment first, so constants aren't redefined by
+	// the pattern.
+	environment := i.environment.extend()
+	if !i.matchPattern(stmt.pattern, value, environment) {
+		i.context.runtimeError(stmt.keyword, "Value doesn't match destructuring pattern.")
+	}
+	for _, name := range patternBindings(stmt.pattern) {
+		i.environment.declare(name, environment.values[name.lexme], stmt.keyword.tokenType != VAR)
+	}
+	return nil
+}
+
+func (i *Interpreter) visitDestructureExpr(expr *DestructureExpr) Any {
+	value := i.evaluate(expr.value)
+	i.assignPattern(expr.pattern, value, expr.equals)
+	return value
+}
+
+func (i *Interpreter) assignPattern(target Expr, value Any, equals *Token) {
+	switch target := target.(type) {
+	case *VariableExpr:
+		i.assignVariable(target.name, target, value)
+		break
+
+	case *GetExpr:
+		i.setProperty(target.name, i.evaluate(target.object), value)
+		break
+
+	case *IndexExpr:
+		object := i.evaluate(target.object)
+		i.setIndex(target.bracket, object, i.evaluate(target.index), value)
+		break
+
+	case *ListExpr:
+		list, ok := value.(*LoxList)
+		if !ok {
+			i.context.runtimeError(equals, "Only lists can be assigned to list patterns.")
+		}
+
+		// Copy elements first, the list itself may be modified by the
+		// assignment.
+		elements, rest := splitRest(target)
+		values := restOf(list, 0)
+		if rest == nil && len(values.elements) != len(elements) {
+			i.context.runtimeError(equals, "Expected %v elements but got %v.", len(elements), len(values.elements))
+		} else if len(values.elements) < len(elements) {
+			i.context.runtimeError(equals, "Expected at least %v elements but got %v.", len(elements), len(values.elements))
+		}
+
+		for index, element := range elements {
+			i.assignPattern(element, values.elements[index], equals)
+		}
+		if rest != nil {
+			i.assignPattern(rest.expression, restOf(values, len(elements)), equals)
+		}
+		break
+
+	case *MapExpr:
+		for index, key := range target.keys {
+			name := i.evaluate(key)
+			entry, ok := entryOf(value, name)
+			if !ok {
+				i.context.runtimeError(equals, "Missing entry %s in assigned value.", repr(name))
+			}
+			i.assignPattern(target.values[index], entry, equals)
+		}
+		break
+	}
+}
@@ -77,6 +77,14 @@ func (p *AstPrinter) visitGroupingExpr(expr *GroupingExpr) Any {
 	return fmt.Sprintf("(%s)", p.print(expr.expression))
 }
 
+func (p *AstPrinter) visitInterpolationExpr(expr *InterpolationExpr) Any {
+	parts := make([]string, len(expr.parts))
+	for index, part := range expr.parts {
+		parts[index] = p.print(part)
+	}
+	return fmt.Sprintf("Interpolation(%s)", strings.Join(parts, " "))
+}
+
 func (p *AstPrinter) visitLiteralExpr(expr *LiteralExpr) Any {
 	return fmt.Sprintf("Literal(%v)", expr.value)
 }
@@ -98,11 +106,21 @@ func (p *AstPrinter) visitExpressionStmt(stmt *ExpressionStmt) Any {
 }
 
 func (p *AstPrinter) visitVarStmt(stmt *VarStmt) Any {
+	if stmt.constant {
+		return fmt.Sprintf("Const(%s = %s)", p.print(stmt.name), p.print(stmt.initializer))
+	}
 	return fmt.Sprintf("Var(%s = %s)", p.print(stmt.name), p.print(stmt.initializer))
 }
 
 func (p *AstPrinter) visitAssignExpr(expr *AssignExpr) Any {
-	return fmt.Sprintf("Assign(%s = %s)", p.print(expr.name), p.print(expr.value))
+	return fmt.Sprintf("Assign(%s %s %s)", p.print(expr.name), p.printAssignOperator(expr.operator), p.print(expr.value))
+}
+
+func (p *AstPrinter) printAssignOperator(operator *Token) string {
+	if operator == nil {
+		return "="
+	}
+	return operator.lexme
 }
 
 func (p *AstPrinter) visitBlockStmt(stmt *BlockStmt) Any {
@@ -117,23 +135,45 @@ func (p *AstPrinter) visitLogicalExpr(expr *LogicalExpr) Any {
 	return fmt.Sprintf("Logical(%s %s %s)", p.print(expr.left), p.print(expr.operator), p.print(expr.right))
 }
 
+func (p *AstPrinter) visitConditionalExpr(expr *ConditionalExpr) Any {
+	return fmt.Sprintf("Conditional(%s ? %s : %s)", p.print(expr.condition), p.print(expr.thenBranch), p.print(expr.elseBranch))
+}
+
+func (p *AstPrinter) visitYieldStmt(stmt *YieldStmt) Any {
+	return fmt.Sprintf("Yield(%s)", p.print(stmt.value))
+}
+
 func (p *AstPrinter) visitWhileStmt(stmt *WhileStmt) Any {
 	return fmt.Sprintf("While(%s) {%s}", p.print(stmt.condition), p.print(stmt.body))
 }
 
 func (p *AstPrinter) visitCallExpr(expr *CallExpr) Any {
-	arguments := make([]string, len(expr.arguments)+1)
-	arguments[0] = p.print(expr.callee)
-
+	arguments := make([]string, len(expr.arguments))
 	for index, arg := range expr.arguments {
-		arguments[index+1] = p.print(arg)
+		if name := expr.names[index]; name != nil {
+			arguments[index] = fmt.Sprintf("%s: %s", p.print(name), p.print(arg))
+		} else {
+			arguments[index] = p.print(arg)
+		}
 	}
 
-	return fmt.Sprintf("Call(%s (%s))", p.print(expr.callee), p.print(expr.arguments))
+	return fmt.Sprintf("Call(%s (%s))", p.print(expr.callee), strings.Join(arguments, ", "))
 }
 
 func (p *AstPrinter) visitFunctionExpr(expr *FunctionExpr) Any {
-	return fmt.Sprintf("Function(%s (%s)) {%s}", p.print(expr.name), p.print(expr.params), p.print(expr.body))
+	params := make([]string, 0, len(expr.params)+1)
+	for index, param := range expr.params {
+		if value := expr.defaults[index]; value != nil {
+			params = append(params, fmt.Sprintf("%s = %s", p.print(param), p.print(value)))
+		} else {
+			params = append(params, p.print(param))
+		}
+	}
+	if expr.rest != nil {
+		params = append(params, "..."+p.print(expr.rest))
+	}
+
+	return fmt.Sprintf("Function(%s (%s)) {%s}", p.print(expr.name), strings.Join(params, ", "), p.print(expr.body))
 }
 
 func (p *AstPrinter) visitReturnStmt(stmt *ReturnStmt) Any {
@@ -141,15 +181,144 @@ func (p *AstPrinter) visitReturnStmt(stmt *ReturnStmt) Any {
 }
 
 func (p *AstPrinter) visitClassStmt(stmt *ClassStmt) Any {
-	return fmt.Sprintf("Class(%s < %s) {%s}", p.print(stmt.name), p.print(stmt.superclass), p.print(stmt.methods))
+	members := make([]string, 0, len(stmt.fields)+len(stmt.methods)+len(stmt.abstractMethods)+len(stmt.getters)+len(stmt.setters)+len(stmt.staticFields)+len(stmt.staticMethods))
+	for _, field := range stmt.fields {
+		members = append(members, p.print(field))
+	}
+	for _, method := range stmt.methods {
+		members = append(members, p.print(method))
+	}
+	for _, method := range stmt.abstractMethods {
+		members = append(members, fmt.Sprintf("Abstract(%s)", p.print(method)))
+	}
+	for _, getter := range stmt.getters {
+		members = append(members, fmt.Sprintf("Getter(%s)", p.print(getter)))
+	}
+	for _, setter := range stmt.setters {
+		members = append(members, fmt.Sprintf("Setter(%s)", p.print(setter)))
+	}
+	for _, field := range stmt.staticFields {
+		members = append(members, fmt.Sprintf("Static(%s)", p.print(field)))
+	}
+	for _, method := range stmt.staticMethods {
+		members = append(members, fmt.Sprintf("Static(%s)", p.print(method)))
+	}
+
+	header := fmt.Sprintf("%s < %s", p.print(stmt.name), p.print(stmt.superclass))
+	if len(stmt.traits) > 0 {
+		header += " with " + p.printNames(stmt.traits)
+	}
+	if len(stmt.interfaces) > 0 {
+		header += " implements " + p.printNames(stmt.interfaces)
+	}
+	return fmt.Sprintf("Class(%s) {%s}", header, strings.Join(members, ", "))
+}
+
+func (p *AstPrinter) printNames(names []*VariableExpr) string {
+	printed := make([]string, len(names))
+	for index, name := range names {
+		printed[index] = p.print(name)
+	}
+	return strings.Join(printed, ", ")
+}
+
+func (p *AstPrinter) visitTraitStmt(stmt *TraitStmt) Any {
+	methods := make([]string, len(stmt.methods))
+	for index, method := range stmt.methods {
+		methods[index] = p.print(method)
+	}
+	return fmt.Sprintf("Trait(%s) {%s}", p.print(stmt.name), strings.Join(methods, ", "))
+}
+
+func (p *AstPrinter) visitEnumStmt(stmt *EnumStmt) Any {
+	members := make([]string, len(stmt.members))
+	for index, member := range stmt.members {
+		members[index] = p.print(member)
+		if params := stmt.params[index]; len(params) > 0 {
+			values := make([]string, len(params))
+			for position, param := range params {
+				values[position] = p.print(param)
+			}
+			members[index] += "(" + strings.Join(values, ", ") + ")"
+		}
+	}
+	return fmt.Sprintf("Enum(%s) {%s}", p.print(stmt.name), strings.Join(members, ", "))
+}
+
+func (p *AstPrinter) visitInterfaceStmt(stmt *InterfaceStmt) Any {
+	methods := make([]string, len(stmt.methods))
+	for index, method := range stmt.methods {
+		methods[index] = p.print(method)
+	}
+	return fmt.Sprintf("Interface(%s) {%s}", p.print(stmt.name), strings.Join(methods, ", "))
 }
 
 func (p *AstPrinter) visitGetExpr(expr *GetExpr) Any {
+	if expr.optional {
+		return fmt.Sprintf("Get(%s?.%s)", p.print(expr.object), p.print(expr.name))
+	}
 	return fmt.Sprintf("Get(%s.%s)", p.print(expr.object), p.print(expr.name))
 }
 
 func (p *AstPrinter) visitSetExpr(expr *SetExpr) Any {
-	return fmt.Sprintf("Set(%s.%s = %s)", p.print(expr.object), p.print(expr.name), p.print(expr.value))
+	return fmt.Sprintf("Set(%s.%s %s %s)", p.print(expr.object), p.print(expr.name), p.printAssignOperator(expr.operator), p.print(expr.value))
+}
+
+func (p *AstPrinter) visitListExpr(expr *ListExpr) Any {
+	return fmt.Sprintf("List(%s)", p.print(expr.elements))
+}
+
+func (p *AstPrinter) visitMapExpr(expr *MapExpr) Any {
+	entries := make([]string, len(expr.keys))
+	for index, key := range expr.keys {
+		if expr.values[index] == nil {
+			entries[index] = p.print(key)
+			continue
+		}
+		entries[index] = fmt.Sprintf("%s: %s", p.print(key), p.print(expr.values[index]))
+	}
+	return fmt.Sprintf("Map(%s)", strings.Join(entries, ", "))
+}
+
+func (p *AstPrinter) visitSpreadExpr(expr *SpreadExpr) Any {
+	return fmt.Sprintf("Spread(%s)", p.print(expr.expression))
+}
+
+func (p *AstPrinter) visitDestructureExpr(expr *DestructureExpr) Any {
+	return fmt.Sprintf("Destructure(%s = %s)", p.print(expr.pattern), p.print(expr.value))
+}
+
+func (p *AstPrinter) visitDestructureStmt(stmt *DestructureStmt) Any {
+	if stmt.keyword.tokenType != VAR {
+		return fmt.Sprintf("Const(%s = %s)", p.print(stmt.pattern), p.print(stmt.initializer))
+	}
+	return fmt.Sprintf("Var(%s = %s)", p.print(stmt.pattern), p.print(stmt.initializer))
+}
+
+func (p *AstPrinter) visitIndexExpr(expr *IndexExpr) Any {
+	return fmt.Sprintf("Index(%s[%s])", p.print(expr.object), p.print(expr.index))
+}
+
+func (p *AstPrinter) visitRangeExpr(expr *RangeExpr) Any {
+	if expr.step != nil {
+		return fmt.Sprintf("Range(%s %s %s step %s)", p.print(expr.start), expr.operator.lexme, p.print(expr.end), p.print(expr.step))
+	}
+	return fmt.Sprintf("Range(%s %s %s)", p.print(expr.start), expr.operator.lexme, p.print(expr.end))
+}
+
+func (p *AstPrinter) visitSliceExpr(expr *SliceExpr) Any {
+	return fmt.Sprintf("Slice(%s[%s:%s:%s])", p.print(expr.object), p.print(expr.start), p.print(expr.end), p.print(expr.step))
+}
+
+func (p *AstPrinter) visitSetIndexExpr(expr *SetIndexExpr) Any {
+	return fmt.Sprintf("SetIndex(%s[%s] %s %s)", p.print(expr.object), p.print(expr.index), p.printAssignOperator(expr.operator), p.print(expr.value))
+}
+
+func (p *AstPrinter) visitUpdateExpr(expr *UpdateExpr) Any {
+	if expr.prefix {
+		return fmt.Sprintf("Update(%s %s)", expr.operator.lexme, p.print(expr.target))
+	}
+	return fmt.Sprintf("Update(%s %s)", p.print(expr.target), expr.operator.lexme)
 }
 
 func (p *AstPrinter) visitThisExpr(expr *ThisExpr) Any {
@@ -164,6 +333,10 @@ func (p *AstPrinter) visitForStmt(stmt *ForStmt) Any {
 	return fmt.Sprintf("For(%s; %s; %s) {%s}", p.print(stmt.initializer), p.print(stmt.condition), p.print(stmt.increment), p.print(stmt.body))
 }
 
+func (p *AstPrinter) visitForInStmt(stmt *ForInStmt) Any {
+	return fmt.Sprintf("ForIn(%s in %s) {%s}", p.print(stmt.name), p.print(stmt.iterable), p.print(stmt.body))
+}
+
 func (p *AstPrinter) visitContinueStmt(stmt *ContinueStmt) Any {
 	return "Continue"
 }
@@ -175,3 +348,15 @@ func (p *AstPrinter) visitBreakStmt(stmt *BreakStmt) Any {
 func (p *AstPrinter) visitIncludeStmt(stmt *IncludeStmt) Any {
 	return fmt.Sprintf("Include(%s)", p.print(stmt.path))
 }
+
+func (p *AstPrinter) visitMatchStmt(stmt *MatchStmt) Any {
+	cases := make([]string, len(stmt.patterns))
+	for index, alternatives := range stmt.patterns {
+		guard := ""
+		if stmt.guards[index] != nil {
+			guard = " if " + p.print(stmt.guards[index])
+		}
+		cases[index] = fmt.Sprintf("Case(%s%s) {%s}", p.print(alternatives), guard, p.print(stmt.bodies[index]))
+	}
+	return fmt.Sprintf("Match(%s) {%s}", p.print(stmt.subject), strings.Join(cases, "; "))
+}
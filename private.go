@@ -0,0 +1,82 @@
+package main
+
+import "strings"
+
+// PRIVATE_CLASS names variable holding class of the method being executed,
+// defined next to "this". It can't clash with user variables as those can't
+// have private names.
+const PRIVATE_CLASS = "#class"
+
+// isPrivate reports whether name is name of private member.
+func isPrivate(name *Token) bool {
+	return strings.HasPrefix(name.lexme, "#")
+}
+
+// privateFields returns private fields of instance declared by klass.
+func (i *LoxInstance) privateFields(klass *LoxClass) map[string]Any {
+	if i.privates == nil {
+		i.privates = make(map[*LoxClass]map[string]Any)
+	}
+	if _, ok := i.privates[klass]; !ok {
+		i.privates[klass] = make(map[string]Any)
+	}
+	return i.privates[klass]
+}
+
+// privateClass returns class whose method is being executed, only its
+// private members can be accessed.
+func (i *Interpreter) privateClass(name *Token) *LoxClass {
+	for environment := i.environment; environment != nil; environment = environment.enclosing {
+		if klass, ok := environment.values[PRIVATE_CLASS]; ok {
+			return klass.(*LoxClass)
+		}
+	}
+
+	i.context.runtimeError(name, "Can't access private member '%s' outside of its class.", name.lexme)
+	return nil
+}
+
+// privateInstance returns object as instance of the class executing the
+// current method.
+func (i *Interpreter) privateInstance(name *Token, object Any) (*LoxInstance, *LoxClass) {
+	klass := i.privateClass(name)
+	instance, ok := object.(*LoxInstance)
+	if !ok || !instance.klass.isSubclassOf(klass) {
+		i.context.runtimeError(name, "Can't access private member '%s' of object which isn't '%s' instance.", name.lexme, klass.name)
+	}
+	return instance, klass
+}
+
+func (i *Interpreter) getPrivate(name *Token, object Any) Any {
+	instance, klass := i.privateInstance(name, object)
+
+	if value, ok := instance.privateFields(klass)[name.lexme]; ok {
+		return value
+	}
+	if getter, ok := klass.getters[name.lexme]; ok {
+		return getter.bind(instance).Call(i, []Any{})
+	}
+	if method, ok := klass.methods[name.lexme]; ok {
+		return method.bind(instance)
+	}
+
+	i.context.runtimeError(name, "Undefined private member '%s'.", name.lexme)
+	return nil
+}
+
+func (i *Interpreter) setPrivate(name *Token, object Any, value Any) {
+	instance, klass := i.privateInstance(name, object)
+	if instance.frozen {
+		i.context.runtimeError(name, "Can't modify frozen '%s' instance.", instance.klass.name)
+	}
+
+	if setter, ok := klass.setters[name.lexme]; ok {
+		setter.bind(instance).Call(i, []Any{value})
+		return
+	}
+	if _, ok := klass.getters[name.lexme]; ok {
+		i.context.runtimeError(name, "Private property '%s' has no setter.", name.lexme)
+	}
+
+	instance.privateFields(klass)[name.lexme] = value
+}
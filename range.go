@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// LoxRange is a lazy sequence of integers from start to end, end is
+// included unless the range is exclusive.
+type LoxRange struct {
+	start     int64
+	end       int64
+	step      int64
+	inclusive bool
+}
+
+func MakeLoxRange(start int64, end int64, step int64, inclusive bool) *LoxRange {
+	return &LoxRange{start: start, end: end, step: step, inclusive: inclusive}
+}
+
+func (r *LoxRange) String() string {
+	operator := "..<"
+	if r.inclusive {
+		operator = ".."
+	}
+
+	if r.step != 1 {
+		return fmt.Sprintf("%v%s%v step %v", r.start, operator, r.end, r.step)
+	}
+	return fmt.Sprintf("%v%s%v", r.start, operator, r.end)
+}
+
+// contains reports whether value lies within bounds of the range, in the
+// direction of its step.
+func (r *LoxRange) contains(value int64) bool {
+	if r.step > 0 {
+		return value < r.end || (r.inclusive && value == r.end)
+	}
+	return value > r.end || (r.inclusive && value == r.end)
+}
+
+type rangeIterator struct {
+	r       *LoxRange
+	current int64
+	done    bool
+}
+
+func (r *LoxRange) iterator() LoxIterator {
+	return &rangeIterator{r: r, current: r.start, done: !r.contains(r.start)}
+}
+
+func (it *rangeIterator) hasNext() bool {
+	return !it.done
+}
+
+func (it *rangeIterator) next() Any {
+	value := it.current
+
+	next, ok := addInt(it.current, it.r.step)
+	if !ok || !it.r.contains(next) {
+		it.done = true
+	}
+	it.current = next
+
+	return value
+}
+
+func (i *Interpreter) visitRangeExpr(expr *RangeExpr) Any {
+	start := i.rangeBound(expr.operator, i.evaluate(expr.start))
+	end := i.rangeBound(expr.operator, i.evaluate(expr.end))
+
+	var step int64 = 1
+	if expr.step != nil {
+		step = i.rangeBound(expr.operator, i.evaluate(expr.step))
+		if step == 0 {
+			i.context.runtimeError(expr.operator, "Range step can't be zero.")
+		}
+	}
+
+	return MakeLoxRange(start, end, step, expr.operator.tokenType == DOT_DOT)
+}
+
+func (i *Interpreter) rangeBound(operator *Token, value Any) int64 {
+	switch value := value.(type) {
+	case int64:
+		return value
+	case *big.Int:
+		i.context.runtimeError(operator, "Range bounds and step must fit in 64 bits.")
+	}
+	i.context.runtimeError(operator, "Range bounds and step must be integers.")
+	return 0
+}
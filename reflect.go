@@ -0,0 +1,84 @@
+package main
+
+import (
+	"math/big"
+	"sort"
+	"strings"
+)
+
+// typeName returns name of the type of value, instances are named by their
+// class.
+func typeName(value Any) string {
+	switch value := value.(type) {
+	case nil:
+		return "nil"
+	case bool:
+		return "boolean"
+	case int64, *big.Int:
+		return "integer"
+	case float:
+		return "float"
+	case *Decimal:
+		return "decimal"
+	case string:
+		return "string"
+	case *LoxList:
+		return "list"
+	case *LoxMap:
+		return "map"
+	case *LoxRange:
+		return "range"
+	case *LoxGenerator:
+		return "generator"
+	case *LoxFunction, *LoxStaticCallable:
+		return "function"
+	case *LoxClass:
+		return "class"
+	case *LoxTrait:
+		return "trait"
+	case *LoxInterface:
+		return "interface"
+	case *LoxEnum:
+		return "enum"
+	case *LoxInstance:
+		return value.klass.name
+	case *LoxEnumMember:
+		return value.enum.name
+	}
+	return "unknown"
+}
+
+// isInstance implements "is" operator, which checks whether value is an
+// instance of class, of its subclass, or of class using trait or
+// implementing interface. It also checks whether value is member of enum.
+func (i *Interpreter) isInstance(operator *Token, value Any, kind Any) bool {
+	instance, ok := value.(*LoxInstance)
+
+	switch kind := kind.(type) {
+	case *LoxClass:
+		return ok && instance.klass.isSubclassOf(kind)
+	case *LoxTrait:
+		return ok && instance.klass.hasTrait(kind)
+	case *LoxInterface:
+		return ok && instance.klass.implements(kind)
+	case *LoxEnum:
+		member, ok := value.(*LoxEnumMember)
+		return ok && member.enum == kind
+	}
+
+	i.context.runtimeError(operator, "Right operand of 'is' must be a class, trait, interface or enum.")
+	return false
+}
+
+// publicNames returns list of sorted names, leaving out private ones.
+func publicNames(names []string) *LoxList {
+	sort.Strings(names)
+
+	elements := make([]Any, 0, len(names))
+	for _, name := range names {
+		if !strings.HasPrefix(name, "#") {
+			elements = append(elements, name)
+		}
+	}
+	return MakeLoxList(elements)
+}
@@ -15,6 +15,7 @@ const (
 	CLASS_NONE     ClassType = "NONE"
 	CLASS_CLASS    ClassType = "CLASS"
 	CLASS_SUBCLASS ClassType = "SUBCLASS"
+	CLASS_TRAIT    ClassType = "TRAIT"
 
 	// loop types
 	LOOP_NONE  LoopType = "NONE"
@@ -23,14 +24,24 @@ const (
 )
 
 type Resolver struct {
-	context         *LoxContext
-	interpreter     *Interpreter
-	scopes          *ResolverStack
-	sourceResolver  SourceResolver
-	currentFunction FunctionType
-	currentClass    ClassType
-	currentLoop     LoopType
-	includedFiles   map[string]bool
+	context        *LoxContext
+	interpreter    *Interpreter
+	scopes         *ResolverStack
+	constants      *ResolverStack
+	sourceResolver SourceResolver
+
+	// enums holds enum declarations of each scope, and globalEnums those
+	// of the global scope, match statements are checked against them.
+	enums       []map[string]*EnumStmt
+	globalEnums map[string]*EnumStmt
+
+	currentFunction  FunctionType
+	currentClass     ClassType
+	currentLoop      LoopType
+	currentGenerator bool
+	currentStatic    bool
+	currentPrivates  map[string]bool
+	includedFiles    map[string]bool
 }
 
 func MakeResolver(context *LoxContext, interpreter *Interpreter, sourceResolver SourceResolver) *Resolver {
@@ -38,11 +49,13 @@ func MakeResolver(context *LoxContext, interpreter *Interpreter, sourceResolver
 		context:         context,
 		interpreter:     interpreter,
 		scopes:          &ResolverStack{},
+		constants:       &ResolverStack{},
 		sourceResolver:  sourceResolver,
 		currentFunction: FUNCTION_NONE,
 		currentClass:    CLASS_NONE,
 		currentLoop:     LOOP_NONE,
 		includedFiles:   make(map[string]bool),
+		globalEnums:     make(map[string]*EnumStmt),
 	}
 }
 
@@ -97,10 +110,14 @@ func (r *Resolver) resolveExpr(expr Expr) {
 
 func (r *Resolver) beginScope() {
 	r.scopes.Push(make(map[string]bool))
+	r.constants.Push(make(map[string]bool))
+	r.enums = append(r.enums, make(map[string]*EnumStmt))
 }
 
 func (r *Resolver) endScope() {
 	r.scopes.Pop()
+	r.constants.Pop()
+	r.enums = r.enums[:len(r.enums)-1]
 }
 
 func (r *Resolver) visitVarStmt(stmt *VarStmt) Any {
@@ -109,11 +126,26 @@ func (r *Resolver) visitVarStmt(stmt *VarStmt) Any {
 		r.resolveExpr(stmt.initializer)
 	}
 	r.define(stmt.name)
+	if stmt.constant {
+		r.defineConstant(stmt.name)
+	}
 	return nil
 }
 
+// defineConstant marks local variable as constant. Constants in global
+// scope are checked by the interpreter.
+func (r *Resolver) defineConstant(name *Token) {
+	if r.constants.IsEmpty() {
+		return
+	}
+	r.constants.Peek()[name.lexme] = true
+}
+
 func (r *Resolver) declare(name *Token) {
+	r.checkVariableName(name)
 	if r.scopes.IsEmpty() {
+		// Globals can be redefined, e.g. an enum by a variable.
+		delete(r.globalEnums, name.lexme)
 		return
 	}
 	scope := r.scopes.Peek()
@@ -130,7 +162,15 @@ func (r *Resolver) define(name *Token) {
 	r.scopes.Peek()[name.lexme] = true
 }
 
+// checkVariableName reports private names used as names of variables.
+func (r *Resolver) checkVariableName(name *Token) {
+	if isPrivate(name) {
+		r.context.tokenError(name, "Private names can only be used for class members.")
+	}
+}
+
 func (r *Resolver) visitVariableExpr(expr *VariableExpr) Any {
+	r.checkVariableName(expr.name)
 	if !r.scopes.IsEmpty() {
 		if val, ok := r.scopes.Peek()[expr.name.lexme]; ok && !val {
 			r.context.tokenError(expr.name, "Can't read local variable in its own initializer.")
@@ -150,8 +190,22 @@ func (r *Resolver) resolveLocal(expr Expr, name *Token) {
 	}
 }
 
+// checkAssignable reports assignments to local constants.
+func (r *Resolver) checkAssignable(name *Token) {
+	for i := r.scopes.Size() - 1; i >= 0; i-- {
+		if _, ok := r.scopes.Get(i)[name.lexme]; ok {
+			if r.constants.Get(i)[name.lexme] {
+				r.context.tokenError(name, "Can't assign to constant '%s'.", name.lexme)
+			}
+			return
+		}
+	}
+}
+
 func (r *Resolver) visitAssignExpr(expr *AssignExpr) Any {
+	r.checkVariableName(expr.name)
 	r.resolveExpr(expr.value)
+	r.checkAssignable(expr.name)
 	r.resolveLocal(expr, expr.name)
 	return nil
 }
@@ -168,16 +222,26 @@ func (r *Resolver) visitFunctionExpr(expr *FunctionExpr) Any {
 
 func (r *Resolver) resolveFunction(function *FunctionExpr, functionType FunctionType) {
 	enclosingFunction := r.currentFunction
+	enclosingGenerator := r.currentGenerator
 	r.currentFunction = functionType
+	r.currentGenerator = function.generator
 
 	r.beginScope()
-	for _, param := range function.params {
+	for index, param := range function.params {
+		if value := function.defaults[index]; value != nil {
+			r.resolveExpr(value)
+		}
 		r.declare(param)
 		r.define(param)
 	}
+	if function.rest != nil {
+		r.declare(function.rest)
+		r.define(function.rest)
+	}
 	r.resolve(function.body)
 	r.endScope()
 	r.currentFunction = enclosingFunction
+	r.currentGenerator = enclosingGenerator
 }
 
 func (r *Resolver) visitExpressionStmt(stmt *ExpressionStmt) Any {
@@ -202,6 +266,8 @@ func (r *Resolver) visitReturnStmt(stmt *ReturnStmt) Any {
 	if stmt.value != nil {
 		if r.currentFunction == FUNCTION_INITIALIZER {
 			r.context.tokenError(stmt.keyword, "Can't return a value from an initializer.")
+		} else if r.currentGenerator {
+			r.context.tokenError(stmt.keyword, "Can't return a value from a generator.")
 		}
 
 		r.resolveExpr(stmt.value)
@@ -209,6 +275,19 @@ func (r *Resolver) visitReturnStmt(stmt *ReturnStmt) Any {
 	return nil
 }
 
+func (r *Resolver) visitYieldStmt(stmt *YieldStmt) Any {
+	if r.currentFunction == FUNCTION_NONE {
+		r.context.tokenError(stmt.keyword, "Can't yield from top-level code.")
+	} else if r.currentFunction == FUNCTION_INITIALIZER {
+		r.context.tokenError(stmt.keyword, "Can't yield from an initializer.")
+	}
+
+	if stmt.value != nil {
+		r.resolveExpr(stmt.value)
+	}
+	return nil
+}
+
 func (r *Resolver) visitWhileStmt(stmt *WhileStmt) Any {
 	r.resolveExpr(stmt.condition)
 	r.resolveLoopBody(LOOP_WHILE, stmt.body)
@@ -229,6 +308,17 @@ func (r *Resolver) visitForStmt(stmt *ForStmt) Any {
 	return nil
 }
 
+func (r *Resolver) visitForInStmt(stmt *ForInStmt) Any {
+	r.resolveExpr(stmt.iterable)
+
+	r.beginScope()
+	r.declare(stmt.name)
+	r.define(stmt.name)
+	r.resolveLoopBody(LOOP_FOR, stmt.body)
+	r.endScope()
+	return nil
+}
+
 func (r *Resolver) resolveLoopBody(loopType LoopType, stmt Stmt) {
 	enclosingLoop := r.currentLoop
 	r.currentLoop = loopType
@@ -255,6 +345,13 @@ func (r *Resolver) visitGroupingExpr(expr *GroupingExpr) Any {
 	return nil
 }
 
+func (r *Resolver) visitInterpolationExpr(expr *InterpolationExpr) Any {
+	for _, part := range expr.parts {
+		r.resolveExpr(part)
+	}
+	return nil
+}
+
 func (r *Resolver) visitLiteralExpr(expr *LiteralExpr) Any {
 	return nil
 }
@@ -265,6 +362,13 @@ func (r *Resolver) visitLogicalExpr(expr *LogicalExpr) Any {
 	return nil
 }
 
+func (r *Resolver) visitConditionalExpr(expr *ConditionalExpr) Any {
+	r.resolveExpr(expr.condition)
+	r.resolveExpr(expr.thenBranch)
+	r.resolveExpr(expr.elseBranch)
+	return nil
+}
+
 func (r *Resolver) visitUnaryExpr(expr *UnaryExpr) Any {
 	r.resolveExpr(expr.right)
 	return nil
@@ -276,8 +380,8 @@ func (r *Resolver) visitPrintStmt(stmt *PrintStmt) Any {
 }
 
 func (r *Resolver) visitClassStmt(stmt *ClassStmt) Any {
-	enclosingClass := r.currentClass
-	r.currentClass = CLASS_CLASS
+	enclosingClass, enclosingStatic, enclosingPrivates := r.currentClass, r.currentStatic, r.currentPrivates
+	r.currentClass, r.currentStatic, r.currentPrivates = CLASS_CLASS, false, privateMembers(stmt)
 
 	r.declare(stmt.name)
 	r.define(stmt.name)
@@ -290,6 +394,9 @@ func (r *Resolver) visitClassStmt(stmt *ClassStmt) Any {
 		r.currentClass = CLASS_SUBCLASS
 		r.resolveExpr(stmt.superclass)
 	}
+	r.resolveTraits(stmt)
+	r.resolveInterfaces(stmt)
+	r.checkAbstract(stmt)
 
 	if stmt.superclass != nil {
 		r.beginScope()
@@ -299,6 +406,12 @@ func (r *Resolver) visitClassStmt(stmt *ClassStmt) Any {
 	r.beginScope()
 	r.scopes.Peek()["this"] = true
 
+	for _, field := range stmt.fields {
+		if field.initializer != nil {
+			r.resolveExpr(field.initializer)
+		}
+	}
+
 	for _, method := range stmt.methods {
 		declaration := FUNCTION_METHOD
 
@@ -310,24 +423,150 @@ func (r *Resolver) visitClassStmt(stmt *ClassStmt) Any {
 
 		r.resolveFunction(method, declaration)
 	}
+	for _, getter := range stmt.getters {
+		r.resolveFunction(getter, FUNCTION_METHOD)
+	}
+	for _, setter := range stmt.setters {
+		r.resolveFunction(setter, FUNCTION_METHOD)
+	}
 
 	r.endScope()
 
+	// Static members aren't bound to instances, so they're resolved
+	// outside of the scope defining "this".
+	r.currentStatic = true
+	for _, method := range stmt.staticMethods {
+		if method.name == nil {
+			r.context.tokenError(method.paren, "Method must have a name.")
+		}
+		r.resolveFunction(method, FUNCTION_METHOD)
+	}
+	for _, field := range stmt.staticFields {
+		if field.initializer != nil {
+			r.resolveExpr(field.initializer)
+		}
+	}
+	r.currentStatic = false
+
 	if stmt.superclass != nil {
 		r.endScope()
 	}
 
-	r.currentClass = enclosingClass
+	r.currentClass, r.currentStatic, r.currentPrivates = enclosingClass, enclosingStatic, enclosingPrivates
+	return nil
+}
+
+// privateMembers returns names of private members declared by class.
+func privateMembers(stmt *ClassStmt) map[string]bool {
+	privates := make(map[string]bool)
+	for _, field := range stmt.fields {
+		if isPrivate(field.name) {
+			privates[field.name.lexme] = true
+		}
+	}
+	for _, methods := range [][]*FunctionExpr{stmt.methods, stmt.getters, stmt.setters} {
+		for _, method := range methods {
+			if method.name != nil && isPrivate(method.name) {
+				privates[method.name.lexme] = true
+			}
+		}
+	}
+	return privates
+}
+
+// checkPrivate reports access to private member name of object, which is
+// only allowed through "this" in body of the declaring class.
+func (r *Resolver) checkPrivate(object Expr, name *Token) {
+	if !isPrivate(name) {
+		return
+	}
+
+	if r.currentClass == CLASS_NONE {
+		r.context.tokenError(name, "Can't access private member '%s' outside of its class.", name.lexme)
+	} else if _, ok := object.(*ThisExpr); !ok {
+		r.context.tokenError(name, "Private member '%s' can only be accessed through 'this'.", name.lexme)
+	} else if !r.currentPrivates[name.lexme] {
+		r.context.tokenError(name, "Private member '%s' isn't declared in this class.", name.lexme)
+	}
+}
+
+func (r *Resolver) visitListExpr(expr *ListExpr) Any {
+	for _, element := range expr.elements {
+		r.resolveExpr(element)
+	}
+	return nil
+}
+
+func (r *Resolver) visitMapExpr(expr *MapExpr) Any {
+	for index, key := range expr.keys {
+		r.resolveExpr(key)
+		if value := expr.values[index]; value != nil {
+			r.resolveExpr(value)
+		}
+	}
+	return nil
+}
+
+func (r *Resolver) visitSpreadExpr(expr *SpreadExpr) Any {
+	r.resolveExpr(expr.expression)
+	return nil
+}
+
+func (r *Resolver) visitRangeExpr(expr *RangeExpr) Any {
+	r.resolveExpr(expr.start)
+	r.resolveExpr(expr.end)
+	if expr.step != nil {
+		r.resolveExpr(expr.step)
+	}
+	return nil
+}
+
+func (r *Resolver) visitSliceExpr(expr *SliceExpr) Any {
+	r.resolveExpr(expr.object)
+	for _, bound := range []Expr{expr.start, expr.end, expr.step} {
+		if bound != nil {
+			r.resolveExpr(bound)
+		}
+	}
+	return nil
+}
+
+func (r *Resolver) visitIndexExpr(expr *IndexExpr) Any {
+	r.resolveExpr(expr.object)
+	r.resolveExpr(expr.index)
+	return nil
+}
+
+func (r *Resolver) visitSetIndexExpr(expr *SetIndexExpr) Any {
+	r.resolveExpr(expr.object)
+	r.resolveExpr(expr.index)
+	r.resolveExpr(expr.value)
+	return nil
+}
+
+func (r *Resolver) visitUpdateExpr(expr *UpdateExpr) Any {
+	switch target := expr.target.(type) {
+	case *VariableExpr:
+		r.checkAssignable(target.name)
+		r.resolveLocal(expr, target.name)
+		break
+	default:
+		// Resolve sub-expressions of the target, e.g. object of a property.
+		r.resolveExpr(target)
+		break
+	}
 	return nil
 }
 
 func (r *Resolver) visitGetExpr(expr *GetExpr) Any {
 	r.resolveExpr(expr.object)
+	r.checkPrivate(expr.object, expr.name)
 	return nil
 }
 
 func (r *Resolver) visitSetExpr(expr *SetExpr) Any {
 	r.resolveExpr(expr.object)
+	r.checkPrivate(expr.object, expr.name)
 	r.resolveExpr(expr.value)
 	return nil
 }
@@ -337,6 +576,10 @@ func (r *Resolver) visitThisExpr(expr *ThisExpr) Any {
 		r.context.tokenError(expr.keyword, "Can't use 'this' outside of a class.")
 		return nil
 	}
+	if r.currentStatic {
+		r.context.tokenError(expr.keyword, "Can't use 'this' in a static member.")
+		return nil
+	}
 
 	r.resolveLocal(expr, expr.keyword)
 	return nil
@@ -345,8 +588,12 @@ func (r *Resolver) visitThisExpr(expr *ThisExpr) Any {
 func (r *Resolver) visitSuperExpr(expr *SuperExpr) Any {
 	if r.currentClass == CLASS_NONE {
 		r.context.tokenError(expr.keyword, "Can't use 'super' outside of a class.")
+	} else if r.currentClass == CLASS_TRAIT {
+		r.context.tokenError(expr.keyword, "Can't use 'super' in a trait.")
 	} else if r.currentClass != CLASS_SUBCLASS {
 		r.context.tokenError(expr.keyword, "Can't use 'super' in class with no superclass.")
+	} else if r.currentStatic {
+		r.context.tokenError(expr.keyword, "Can't use 'super' in a static member.")
 	}
 
 	r.resolveLocal(expr, expr.keyword)
@@ -2,39 +2,71 @@ package main
 
 import (
 	"fmt"
+	"math/big"
 	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 type TokenType string
 
 const (
 	// Single-character tokens.
-	LEFT_PAREN  TokenType = "LEFT_PAREN"
-	RIGHT_PAREN TokenType = "RIGHT_PAREN"
-	LEFT_BRACE  TokenType = "LEFT_BRACE"
-	RIGHT_BRACE TokenType = "RIGHT_BRACE"
-	COMMA       TokenType = "COMMA"
-	DOT         TokenType = "DOT"
-	MINUS       TokenType = "MINUS"
-	PLUS        TokenType = "PLUS"
-	SEMICOLON   TokenType = "SEMICOLON"
-	SLASH       TokenType = "SLASH"
-	STAR        TokenType = "STAR"
+	LEFT_PAREN    TokenType = "LEFT_PAREN"
+	RIGHT_PAREN   TokenType = "RIGHT_PAREN"
+	LEFT_BRACE    TokenType = "LEFT_BRACE"
+	RIGHT_BRACE   TokenType = "RIGHT_BRACE"
+	LEFT_BRACKET  TokenType = "LEFT_BRACKET"
+	RIGHT_BRACKET TokenType = "RIGHT_BRACKET"
+	COLON         TokenType = "COLON"
+	COMMA         TokenType = "COMMA"
+	DOT           TokenType = "DOT"
+	MINUS         TokenType = "MINUS"
+	PLUS          TokenType = "PLUS"
+	SEMICOLON     TokenType = "SEMICOLON"
+	SLASH         TokenType = "SLASH"
+	STAR          TokenType = "STAR"
+	PERCENT       TokenType = "PERCENT"
+	AMPERSAND     TokenType = "AMPERSAND"
+	PIPE          TokenType = "PIPE"
+	CARET         TokenType = "CARET"
+	QUESTION      TokenType = "QUESTION"
 
 	// One or two character tokens.
-	BANG          TokenType = "BANG"
-	BANG_EQUAL    TokenType = "BANG_EQUAL"
-	EQUAL         TokenType = "EQUAL"
-	EQUAL_EQUAL   TokenType = "EQUAL_EQUAL"
-	GREATER       TokenType = "GREATER"
-	GREATER_EQUAL TokenType = "GREATER_EQUAL"
-	LESS          TokenType = "LESS"
-	LESS_EQUAL    TokenType = "LESS_EQUAL"
+	BANG              TokenType = "BANG"
+	BANG_EQUAL        TokenType = "BANG_EQUAL"
+	EQUAL             TokenType = "EQUAL"
+	EQUAL_EQUAL       TokenType = "EQUAL_EQUAL"
+	GREATER           TokenType = "GREATER"
+	GREATER_EQUAL     TokenType = "GREATER_EQUAL"
+	LESS              TokenType = "LESS"
+	LESS_EQUAL        TokenType = "LESS_EQUAL"
+	LESS_LESS         TokenType = "LESS_LESS"
+	GREATER_GREATER   TokenType = "GREATER_GREATER"
+	STAR_STAR         TokenType = "STAR_STAR"
+	TILDE             TokenType = "TILDE"
+	TILDE_SLASH       TokenType = "TILDE_SLASH"
+	PLUS_PLUS         TokenType = "PLUS_PLUS"
+	MINUS_MINUS       TokenType = "MINUS_MINUS"
+	PLUS_EQUAL        TokenType = "PLUS_EQUAL"
+	MINUS_EQUAL       TokenType = "MINUS_EQUAL"
+	STAR_EQUAL        TokenType = "STAR_EQUAL"
+	SLASH_EQUAL       TokenType = "SLASH_EQUAL"
+	PERCENT_EQUAL     TokenType = "PERCENT_EQUAL"
+	QUESTION_QUESTION TokenType = "QUESTION_QUESTION"
+	QUESTION_DOT      TokenType = "QUESTION_DOT"
+	ARROW             TokenType = "ARROW"
+	DOT_DOT           TokenType = "DOT_DOT"
+	DOT_DOT_LESS      TokenType = "DOT_DOT_LESS"
+	DOT_DOT_DOT       TokenType = "DOT_DOT_DOT"
 
 	// Literals.
-	IDENTIFIER TokenType = "IDENTIFIER"
-	STRING     TokenType = "STRING"
-	NUMBER     TokenType = "NUMBER"
+	IDENTIFIER    TokenType = "IDENTIFIER"
+	STRING        TokenType = "STRING"
+	INTERPOLATION TokenType = "INTERPOLATION"
+	NUMBER        TokenType = "NUMBER"
+	DOC_COMMENT   TokenType = "DOC_COMMENT"
 
 	// Keywords.
 	AND      TokenType = "AND"
@@ -56,6 +88,13 @@ const (
 	CONTINUE TokenType = "CONTINUE"
 	BREAK    TokenType = "BREAK"
 	INCLUDE  TokenType = "INCLUDE"
+	MATCH    TokenType = "MATCH"
+	CASE     TokenType = "CASE"
+	IN       TokenType = "IN"
+	IS       TokenType = "IS"
+	YIELD    TokenType = "YIELD"
+	CONST    TokenType = "CONST"
+	LET      TokenType = "LET"
 
 	EOF TokenType = "EOF"
 )
@@ -80,6 +119,13 @@ var keywords = map[string]TokenType{
 	"continue": CONTINUE,
 	"break":    BREAK,
 	"include":  INCLUDE,
+	"match":    MATCH,
+	"case":     CASE,
+	"in":       IN,
+	"is":       IS,
+	"yield":    YIELD,
+	"const":    CONST,
+	"let":      LET,
 }
 
 type Token struct {
@@ -94,7 +140,7 @@ func (t *Token) String() string {
 	switch value := t.literal.(type) {
 	case string:
 		return fmt.Sprintf("%s %s %s at %v", t.tokenType, t.lexme, value, t.line)
-	case float:
+	case int64, *big.Int, *Decimal, float:
 		return fmt.Sprintf("%s %s %v at %v", t.tokenType, t.lexme, value, t.line)
 	default:
 		return fmt.Sprintf("%s %s at %v", t.tokenType, t.lexme, t.line)
@@ -118,6 +164,10 @@ type Scanner struct {
 	start   int
 	current int
 	line    int
+
+	// interpolations holds number of unclosed braces for each string
+	// interpolation we are currently inside of.
+	interpolations []int
 }
 
 func MakeScanner(context *LoxContext, source *Source) *Scanner {
@@ -132,15 +182,40 @@ func MakeScanner(context *LoxContext, source *Source) *Scanner {
 }
 
 func (s *Scanner) scanTokens() []*Token {
+	if !s.validate() {
+		s.tokens = append(s.tokens, MakeToken(EOF, "", nil, s.line, s.source))
+		return s.tokens
+	}
+
 	for !s.isAtEnd() {
 		s.start = s.current
 		s.scanToken()
 	}
 
+	if len(s.interpolations) > 0 {
+		s.error("Unterminated string interpolation.")
+	}
+
 	s.tokens = append(s.tokens, MakeToken(EOF, "", nil, s.line, s.source))
 	return s.tokens
 }
 
+// validate reports an error at the first byte sequence that is not valid
+// UTF-8, as the scanner decodes source code rune by rune.
+func (s *Scanner) validate() bool {
+	code := s.source.Code
+	for offset := 0; offset < len(code); {
+		r, size := utf8.DecodeRuneInString(code[offset:])
+		if r == utf8.RuneError && size == 1 {
+			s.line = 1 + strings.Count(code[:offset], "\n")
+			s.error("Source is not valid UTF-8 (invalid byte 0x%02x).", code[offset])
+			return false
+		}
+		offset += size
+	}
+	return true
+}
+
 func (s *Scanner) isAtEnd() bool {
 	return s.current >= len(s.source.Code)
 }
@@ -157,27 +232,74 @@ func (s *Scanner) scanToken() {
 		break
 
 	case '{':
+		if depth := len(s.interpolations); depth > 0 {
+			s.interpolations[depth-1]++
+		}
 		s.addToken(LEFT_BRACE)
 		break
 
 	case '}':
+		if depth := len(s.interpolations); depth > 0 {
+			if s.interpolations[depth-1] == 0 {
+				// Closing brace of the interpolated expression, continue
+				// scanning the rest of the string.
+				s.interpolations = s.interpolations[:depth-1]
+				s.string()
+				break
+			}
+			s.interpolations[depth-1]--
+		}
 		s.addToken(RIGHT_BRACE)
 		break
 
+	case '[':
+		s.addToken(LEFT_BRACKET)
+		break
+
+	case ']':
+		s.addToken(RIGHT_BRACKET)
+		break
+
+	case ':':
+		s.addToken(COLON)
+		break
+
 	case ',':
 		s.addToken(COMMA)
 		break
 
 	case '.':
-		s.addToken(DOT)
+		if s.match('.') {
+			if s.match('.') {
+				s.addToken(DOT_DOT_DOT)
+			} else if s.match('<') {
+				s.addToken(DOT_DOT_LESS)
+			} else {
+				s.addToken(DOT_DOT)
+			}
+		} else {
+			s.addToken(DOT)
+		}
 		break
 
 	case '-':
-		s.addToken(MINUS)
+		if s.match('-') {
+			s.addToken(MINUS_MINUS)
+		} else if s.match('=') {
+			s.addToken(MINUS_EQUAL)
+		} else {
+			s.addToken(MINUS)
+		}
 		break
 
 	case '+':
-		s.addToken(PLUS)
+		if s.match('+') {
+			s.addToken(PLUS_PLUS)
+		} else if s.match('=') {
+			s.addToken(PLUS_EQUAL)
+		} else {
+			s.addToken(PLUS)
+		}
 		break
 
 	case ';':
@@ -185,7 +307,52 @@ func (s *Scanner) scanToken() {
 		break
 
 	case '*':
-		s.addToken(STAR)
+		if s.match('*') {
+			s.addToken(STAR_STAR)
+		} else if s.match('=') {
+			s.addToken(STAR_EQUAL)
+		} else {
+			s.addToken(STAR)
+		}
+		break
+
+	case '%':
+		if s.match('=') {
+			s.addToken(PERCENT_EQUAL)
+		} else {
+			s.addToken(PERCENT)
+		}
+		break
+
+	case '&':
+		s.addToken(AMPERSAND)
+		break
+
+	case '|':
+		s.addToken(PIPE)
+		break
+
+	case '^':
+		s.addToken(CARET)
+		break
+
+	case '~':
+		// "//" starts a comment, so integer division is written as "~/".
+		if s.match('/') {
+			s.addToken(TILDE_SLASH)
+		} else {
+			s.addToken(TILDE)
+		}
+		break
+
+	case '?':
+		if s.match('?') {
+			s.addToken(QUESTION_QUESTION)
+		} else if s.match('.') {
+			s.addToken(QUESTION_DOT)
+		} else {
+			s.addToken(QUESTION)
+		}
 		break
 
 	case '!':
@@ -199,6 +366,8 @@ func (s *Scanner) scanToken() {
 	case '=':
 		if s.match('=') {
 			s.addToken(EQUAL_EQUAL)
+		} else if s.match('>') {
+			s.addToken(ARROW)
 		} else {
 			s.addToken(EQUAL)
 		}
@@ -207,6 +376,8 @@ func (s *Scanner) scanToken() {
 	case '<':
 		if s.match('=') {
 			s.addToken(LESS_EQUAL)
+		} else if s.match('<') {
+			s.addToken(LESS_LESS)
 		} else {
 			s.addToken(LESS)
 		}
@@ -215,6 +386,8 @@ func (s *Scanner) scanToken() {
 	case '>':
 		if s.match('=') {
 			s.addToken(GREATER_EQUAL)
+		} else if s.match('>') {
+			s.addToken(GREATER_GREATER)
 		} else {
 			s.addToken(GREATER)
 		}
@@ -222,9 +395,17 @@ func (s *Scanner) scanToken() {
 
 	case '/':
 		if s.match('/') {
+			if s.peek() == '/' && s.peekNext() != '/' {
+				s.docComment()
+				break
+			}
 			for s.peek() != '\n' && !s.isAtEnd() {
 				s.advance()
 			}
+		} else if s.match('*') {
+			s.blockComment()
+		} else if s.match('=') {
+			s.addToken(SLASH_EQUAL)
 		} else {
 			s.addToken(SLASH)
 		}
@@ -244,21 +425,34 @@ func (s *Scanner) scanToken() {
 		s.string()
 		break
 
+	case '#':
+		// Names of private members are identifiers prefixed with '#'.
+		if isAlpha(s.peek()) {
+			s.identifier()
+		} else {
+			s.error("Unexpected character '%s'.", string(c))
+		}
+		break
+
 	default:
 		if isDigit(c) {
 			s.number()
 		} else if isAlpha(c) {
 			s.identifier()
 		} else {
-			s.context.error(fmt.Sprintf("%s:%v", s.source.Name, s.line), "Unexpected character '%s'.", string(c))
+			s.error("Unexpected character '%s'.", string(c))
 		}
 		break
 	}
 }
 
-func (s *Scanner) advance() byte {
-	c := s.source.Code[s.current]
-	s.current++
+func (s *Scanner) error(message string, a ...interface{}) {
+	s.context.error(fmt.Sprintf("%s:%v", s.source.Name, s.line), message, a...)
+}
+
+func (s *Scanner) advance() rune {
+	c, size := utf8.DecodeRuneInString(s.source.Code[s.current:])
+	s.current += size
 	return c
 }
 
@@ -271,81 +465,289 @@ func (s *Scanner) addLiteralToken(tokenType TokenType, literal interface{}) {
 	s.tokens = append(s.tokens, MakeToken(tokenType, text, literal, s.line, s.source))
 }
 
-func (s *Scanner) match(expected byte) bool {
+func (s *Scanner) match(expected rune) bool {
 	if s.isAtEnd() {
 		return false
 	}
-	if s.source.Code[s.current] != expected {
+	if s.peek() != expected {
 		return false
 	}
-	s.current++
+	s.advance()
 	return true
 }
 
-func (s *Scanner) peek() byte {
+func (s *Scanner) peek() rune {
 	if s.isAtEnd() {
 		return 0
 	}
-	return s.source.Code[s.current]
+	c, _ := utf8.DecodeRuneInString(s.source.Code[s.current:])
+	return c
 }
 
-func (s *Scanner) peekNext() byte {
-	if s.current+1 >= len(s.source.Code) {
+func (s *Scanner) peekNext() rune {
+	if s.isAtEnd() {
 		return 0
 	}
-	return s.source.Code[s.current+1]
+	_, size := utf8.DecodeRuneInString(s.source.Code[s.current:])
+	if s.current+size >= len(s.source.Code) {
+		return 0
+	}
+	c, _ := utf8.DecodeRuneInString(s.source.Code[s.current+size:])
+	return c
+}
+
+// "///" text until the end of line ;
+func (s *Scanner) docComment() {
+	s.advance()
+
+	start := s.current
+	for s.peek() != '\n' && !s.isAtEnd() {
+		s.advance()
+	}
+
+	text := strings.TrimSuffix(s.source.Code[start:s.current], "\r")
+	s.addLiteralToken(DOC_COMMENT, strings.TrimPrefix(text, " "))
+}
+
+// "/*" ( blockComment | any )* "*/" ;
+func (s *Scanner) blockComment() {
+	depth := 1
+	for depth > 0 {
+		if s.isAtEnd() {
+			s.error("Unterminated block comment.")
+			return
+		}
+
+		c := s.advance()
+		switch {
+		case c == '\n':
+			s.line++
+		case c == '/' && s.match('*'):
+			depth++
+		case c == '*' && s.match('/'):
+			depth--
+		}
+	}
 }
 
 func (s *Scanner) string() {
+	var value strings.Builder
+
 	for s.peek() != '"' && !s.isAtEnd() {
-		if s.peek() == '\n' {
+		c := s.advance()
+		switch c {
+		case '\n':
 			s.line++
+			value.WriteRune(c)
+			break
+
+		case '\\':
+			s.escape(&value)
+			break
+
+		case '$':
+			if s.match('{') {
+				// Emit the part before interpolated expression, the rest
+				// of the string will be scanned once '}' is reached.
+				s.interpolations = append(s.interpolations, 0)
+				s.addLiteralToken(INTERPOLATION, value.String())
+				return
+			}
+			value.WriteRune(c)
+			break
+
+		default:
+			value.WriteRune(c)
+			break
 		}
-		s.advance()
 	}
 
 	if s.isAtEnd() {
-		s.context.error(fmt.Sprintf("%s:%v", s.source.Name, s.line), "Unterminated string.")
+		s.error("Unterminated string.")
 		return
 	}
 
 	s.advance()
 
-	value := s.source.Code[s.start+1 : s.current-1]
-	s.addLiteralToken(STRING, value)
+	s.addLiteralToken(STRING, value.String())
 }
 
-func isDigit(char byte) bool {
+func (s *Scanner) escape(value *strings.Builder) {
+	if s.isAtEnd() {
+		return
+	}
+
+	c := s.advance()
+	switch c {
+	case 'n':
+		value.WriteByte('\n')
+	case 't':
+		value.WriteByte('\t')
+	case 'r':
+		value.WriteByte('\r')
+	case '0':
+		value.WriteByte(0)
+	case '\\', '"', '$':
+		value.WriteRune(c)
+	case 'u':
+		s.unicodeEscape(value)
+	default:
+		s.error("Invalid escape sequence '\\%s'.", string(c))
+	}
+}
+
+// \u{XXXX} ;
+func (s *Scanner) unicodeEscape(value *strings.Builder) {
+	if !s.match('{') {
+		s.error("Expect '{' after '\\u'.")
+		return
+	}
+
+	start := s.current
+	for isHexDigit(s.peek()) {
+		s.advance()
+	}
+	digits := s.source.Code[start:s.current]
+
+	if !s.match('}') {
+		s.error("Expect '}' after unicode escape sequence.")
+		return
+	}
+
+	code, err := strconv.ParseUint(digits, 16, 32)
+	if err != nil || len(digits) > 6 || !utf8.ValidRune(rune(code)) {
+		s.error("Invalid unicode escape sequence '\\u{%s}'.", digits)
+		return
+	}
+
+	value.WriteRune(rune(code))
+}
+
+func isHexDigit(char rune) bool {
+	return isDigit(char) || (char >= 'a' && char <= 'f') || (char >= 'A' && char <= 'F')
+}
+
+func isDigit(char rune) bool {
 	return char >= '0' && char <= '9'
 }
 
 func (s *Scanner) number() {
-	for isDigit(s.peek()) {
+	base := 10
+	if s.source.Code[s.start] == '0' {
+		switch s.peek() {
+		case 'x', 'X':
+			base = 16
+		case 'b', 'B':
+			base = 2
+		case 'o', 'O':
+			base = 8
+		}
+	}
+
+	if base != 10 {
+		s.advance()
+		for isHexDigit(s.peek()) || s.peek() == '_' {
+			s.advance()
+		}
+		s.integer(s.source.Code[s.start+2:s.current], base)
+		return
+	}
+
+	for isDigit(s.peek()) || s.peek() == '_' {
 		s.advance()
 	}
 
+	isFloat := false
 	if s.peek() == '.' && isDigit(s.peekNext()) {
+		isFloat = true
 		s.advance()
-		for isDigit(s.peek()) {
+		for isDigit(s.peek()) || s.peek() == '_' {
 			s.advance()
 		}
 	}
 
+	if s.peek() == 'd' && !isAlphaNumeric(s.peekNext()) {
+		s.advance()
+		s.decimal()
+	} else if isFloat {
+		s.float()
+	} else {
+		s.integer(s.source.Code[s.start:s.current], base)
+	}
+}
+
+func (s *Scanner) integer(digits string, base int) {
 	seq := s.source.Code[s.start:s.current]
-	value, err := strconv.ParseFloat(seq, 64)
+	digits, ok := removeSeparators(digits)
+	if !ok {
+		s.error("Invalid digit separator in '%s'.", seq)
+		return
+	}
+
+	value, ok := new(big.Int).SetString(digits, base)
+	if !ok {
+		s.error("Failed to convert '%s' sequence to number.", seq)
+		return
+	}
+
+	s.addLiteralToken(NUMBER, normalizeInteger(value))
+}
+
+func (s *Scanner) float() {
+	seq := s.source.Code[s.start:s.current]
+	digits, ok := removeSeparators(seq)
+	if !ok {
+		s.error("Invalid digit separator in '%s'.", seq)
+		return
+	}
+
+	value, err := strconv.ParseFloat(digits, 64)
 	if err != nil {
-		s.context.error(fmt.Sprintf("%s:%v", s.source.Name, s.line), "Failed to convert '%s' sequence to number.", seq)
-	} else {
-		s.addLiteralToken(NUMBER, value)
+		s.error("Failed to convert '%s' sequence to number.", seq)
+		return
+	}
+
+	s.addLiteralToken(NUMBER, value)
+}
+
+// digits ( "." digits )? "d" ;
+func (s *Scanner) decimal() {
+	seq := s.source.Code[s.start:s.current]
+	digits, ok := removeSeparators(strings.TrimSuffix(seq, "d"))
+	if !ok {
+		s.error("Invalid digit separator in '%s'.", seq)
+		return
+	}
+
+	value, ok := ParseDecimal(digits)
+	if !ok {
+		s.error("Failed to convert '%s' sequence to number.", seq)
+		return
+	}
+
+	s.addLiteralToken(NUMBER, value)
+}
+
+// removeSeparators strips "_" digit separators, which are only allowed
+// between two digits.
+func removeSeparators(seq string) (string, bool) {
+	for index := 0; index < len(seq); index++ {
+		if seq[index] != '_' {
+			continue
+		}
+		if index == 0 || index == len(seq)-1 || !isHexDigit(rune(seq[index-1])) || !isHexDigit(rune(seq[index+1])) {
+			return "", false
+		}
 	}
+	return strings.ReplaceAll(seq, "_", ""), true
 }
 
-func isAlpha(char byte) bool {
-	return (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z') || char == '_'
+func isAlpha(char rune) bool {
+	return unicode.IsLetter(char) || char == '_'
 }
 
-func isAlphaNumeric(char byte) bool {
-	return isAlpha(char) || isDigit(char)
+func isAlphaNumeric(char rune) bool {
+	return isAlpha(char) || unicode.IsDigit(char) || unicode.Is(unicode.Mn, char) || unicode.Is(unicode.Mc, char)
 }
 
 func (s *Scanner) identifier() {
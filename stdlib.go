@@ -1,7 +1,12 @@
 package main
 
 import (
+	"fmt"
 	"io/ioutil"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -9,6 +14,116 @@ func InitializeStdLib(environment *Environment) {
 	environment.define("clock", MakeLoxCallable(0, lox_clock))
 	environment.define("readfile", MakeLoxCallable(1, lox_readfile))
 	environment.define("writefile", MakeLoxCallable(2, lox_writefile))
+
+	// numbers
+	environment.define("int", MakeLoxCallable(1, lox_int))
+	environment.define("float", MakeLoxCallable(1, lox_float))
+	environment.define("decimal", MakeLoxCallable(1, lox_decimal))
+
+	// strings
+	environment.define("len", MakeLoxCallable(1, lox_len))
+	environment.define("substring", MakeLoxCallable(3, lox_substring))
+	environment.define("charAt", MakeLoxCallable(2, lox_charAt))
+	environment.define("reverse", MakeLoxCallable(1, lox_reverse))
+	environment.define("upper", MakeLoxCallable(1, lox_upper))
+	environment.define("lower", MakeLoxCallable(1, lox_lower))
+
+	// collections
+	environment.define("push", MakeLoxCallable(2, lox_push))
+	environment.define("pop", MakeLoxCallable(1, lox_pop))
+	environment.define("keys", MakeLoxCallable(1, lox_keys))
+	environment.define("values", MakeLoxCallable(1, lox_values))
+	environment.define("remove", MakeLoxCallable(2, lox_remove))
+
+	// objects
+	environment.define("freeze", MakeLoxCallable(1, lox_freeze))
+	environment.define("isFrozen", MakeLoxCallable(1, lox_isFrozen))
+
+	// reflection
+	environment.define("type", MakeLoxCallable(1, lox_type))
+	environment.define("fields", MakeLoxCallable(1, lox_fields))
+	environment.define("methods", MakeLoxCallable(1, lox_methods))
+	environment.define("superclass", MakeLoxCallable(1, lox_superclass))
+	environment.define("nameOf", MakeLoxCallable(1, lox_nameOf))
+	environment.define("arity", MakeLoxCallable(1, lox_arity))
+	environment.define("hasField", MakeLoxCallable(2, lox_hasField))
+	environment.define("getField", MakeLoxCallable(2, lox_getField))
+	environment.define("setField", MakeLoxCallable(3, lox_setField))
+}
+
+// NativeError is raised by native functions, the interpreter reports it
+// as a runtime error at the call site.
+type NativeError struct {
+	message string
+}
+
+func nativeError(message string, a ...interface{}) {
+	panic(NativeError{message: fmt.Sprintf(message, a...)})
+}
+
+func stringArgument(arguments []Any, index int) string {
+	if value, ok := arguments[index].(string); ok {
+		return value
+	}
+	nativeError("Argument %v must be a string.", index+1)
+	return ""
+}
+
+func listArgument(arguments []Any, index int) *LoxList {
+	if value, ok := arguments[index].(*LoxList); ok {
+		return value
+	}
+	nativeError("Argument %v must be a list.", index+1)
+	return nil
+}
+
+func mapArgument(arguments []Any, index int) *LoxMap {
+	if value, ok := arguments[index].(*LoxMap); ok {
+		return value
+	}
+	nativeError("Argument %v must be a map.", index+1)
+	return nil
+}
+
+func instanceArgument(arguments []Any, index int) *LoxInstance {
+	if value, ok := arguments[index].(*LoxInstance); ok {
+		return value
+	}
+	nativeError("Argument %v must be an instance.", index+1)
+	return nil
+}
+
+func classArgument(arguments []Any, index int) *LoxClass {
+	if value, ok := arguments[index].(*LoxClass); ok {
+		return value
+	}
+	nativeError("Argument %v must be a class.", index+1)
+	return nil
+}
+
+// fieldArgument returns name of field, which can't be private.
+func fieldArgument(arguments []Any, index int) string {
+	name := stringArgument(arguments, index)
+	if strings.HasPrefix(name, "#") {
+		nativeError("Can't access private member '%s' outside of its class.", name)
+	}
+	return name
+}
+
+// checkMutable raises native error when value passed to native function
+// modifying it is frozen.
+func checkMutable(value LoxFreezable, kind string) {
+	if value.isFrozen() {
+		nativeError("Can't modify frozen %s.", kind)
+	}
+}
+
+func indexArgument(arguments []Any, index int) int {
+	if value, ok := arguments[index].(int64); ok {
+		return int(value)
+	}
+	nativeError("Argument %v must be an integer.", index+1)
+	return 0
 }
 
 func lox_clock(interpreter *Interpreter, arguments []Any) Any {
@@ -16,7 +131,7 @@ func lox_clock(interpreter *Interpreter, arguments []Any) Any {
 }
 
 func lox_readfile(interpreter *Interpreter, arguments []Any) Any {
-	content, err := ioutil.ReadFile(arguments[0].(string))
+	content, err := ioutil.ReadFile(stringArgument(arguments, 0))
 	if err != nil {
 		return nil
 	}
@@ -25,6 +140,278 @@ func lox_readfile(interpreter *Interpreter, arguments []Any) Any {
 }
 
 func lox_writefile(interpreter *Interpreter, arguments []Any) Any {
-	err := ioutil.WriteFile(arguments[0].(string), []byte(arguments[1].(string)), 0644)
+	err := ioutil.WriteFile(stringArgument(arguments, 0), []byte(stringArgument(arguments, 1)), 0644)
 	return err == nil
 }
+
+// int converts floats and decimals (truncating towards zero) and strings to
+// integers.
+func lox_int(interpreter *Interpreter, arguments []Any) Any {
+	switch value := arguments[0].(type) {
+	case int64, *big.Int:
+		return value
+	case *Decimal:
+		return normalizeInteger(value.Int())
+	case float:
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			nativeError("Can't convert %v to integer.", value)
+		}
+		result, _ := big.NewFloat(value).Int(nil)
+		return normalizeInteger(result)
+	case string:
+		result, ok := new(big.Int).SetString(strings.TrimSpace(value), 0)
+		if !ok {
+			nativeError("Can't convert '%s' to integer.", value)
+		}
+		return normalizeInteger(result)
+	}
+	nativeError("Argument 1 must be a number or a string.")
+	return nil
+}
+
+func lox_float(interpreter *Interpreter, arguments []Any) Any {
+	switch value := arguments[0].(type) {
+	case int64, *big.Int, *Decimal, float:
+		return toFloat(value)
+	case string:
+		result, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			nativeError("Can't convert '%s' to float.", value)
+		}
+		return result
+	}
+	nativeError("Argument 1 must be a number or a string.")
+	return nil
+}
+
+// decimal converts numbers and strings to decimals, floats are converted
+// using their shortest textual representation.
+func lox_decimal(interpreter *Interpreter, arguments []Any) Any {
+	switch value := arguments[0].(type) {
+	case int64, *big.Int, *Decimal:
+		return toDecimal(value)
+	case float:
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			nativeError("Can't convert %v to decimal.", value)
+		}
+		result, _ := ParseDecimal(strconv.FormatFloat(value, 'f', -1, 64))
+		return result
+	case string:
+		result, ok := ParseDecimal(strings.TrimSpace(value))
+		if !ok {
+			nativeError("Can't convert '%s' to decimal.", value)
+		}
+		return result
+	}
+	nativeError("Argument 1 must be a number or a string.")
+	return nil
+}
+
+// String natives operate on unicode code points rather than bytes.
+
+func lox_len(interpreter *Interpreter, arguments []Any) Any {
+	switch value := arguments[0].(type) {
+	case *LoxList:
+		return int64(len(value.elements))
+	case *LoxMap:
+		return int64(len(value.order))
+	case string:
+		return int64(len([]rune(value)))
+	}
+	nativeError("Argument 1 must be a string, list or map.")
+	return nil
+}
+
+func lox_substring(interpreter *Interpreter, arguments []Any) Any {
+	runes := []rune(stringArgument(arguments, 0))
+	start := indexArgument(arguments, 1)
+	end := indexArgument(arguments, 2)
+
+	if start < 0 || end > len(runes) || start > end {
+		nativeError("Substring range [%v, %v) is out of bounds for length %v.", start, end, len(runes))
+	}
+
+	return string(runes[start:end])
+}
+
+func lox_charAt(interpreter *Interpreter, arguments []Any) Any {
+	runes := []rune(stringArgument(arguments, 0))
+	index := indexArgument(arguments, 1)
+
+	if index < 0 || index >= len(runes) {
+		nativeError("Index %v is out of bounds for length %v.", index, len(runes))
+	}
+
+	return string(runes[index])
+}
+
+func lox_reverse(interpreter *Interpreter, arguments []Any) Any {
+	runes := []rune(stringArgument(arguments, 0))
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+
+	return string(runes)
+}
+
+func lox_upper(interpreter *Interpreter, arguments []Any) Any {
+	return strings.ToUpper(stringArgument(arguments, 0))
+}
+
+func lox_lower(interpreter *Interpreter, arguments []Any) Any {
+	return strings.ToLower(stringArgument(arguments, 0))
+}
+
+func lox_push(interpreter *Interpreter, arguments []Any) Any {
+	list := listArgument(arguments, 0)
+	checkMutable(list, "list")
+	list.elements = append(list.elements, arguments[1])
+	return int64(len(list.elements))
+}
+
+func lox_pop(interpreter *Interpreter, arguments []Any) Any {
+	list := listArgument(arguments, 0)
+	checkMutable(list, "list")
+	if len(list.elements) == 0 {
+		nativeError("Can't pop from an empty list.")
+	}
+
+	last := list.elements[len(list.elements)-1]
+	list.elements = list.elements[:len(list.elements)-1]
+	return last
+}
+
+func lox_keys(interpreter *Interpreter, arguments []Any) Any {
+	return MakeLoxList(mapArgument(arguments, 0).keys())
+}
+
+func lox_values(interpreter *Interpreter, arguments []Any) Any {
+	return MakeLoxList(mapArgument(arguments, 0).values())
+}
+
+// remove deletes entry from a map and returns its value, or nil when the
+// key is missing.
+func lox_remove(interpreter *Interpreter, arguments []Any) Any {
+	entries := mapArgument(arguments, 0)
+	checkMutable(entries, "map")
+
+	value, _ := entries.remove(arguments[1])
+	return value
+}
+
+// freeze freezes instance, list or map and returns it.
+func lox_freeze(interpreter *Interpreter, arguments []Any) Any {
+	value, ok := arguments[0].(LoxFreezable)
+	if !ok {
+		nativeError("Only instances, lists and maps can be frozen.")
+	}
+
+	value.freeze()
+	return value
+}
+
+func lox_isFrozen(interpreter *Interpreter, arguments []Any) Any {
+	if value, ok := arguments[0].(LoxFreezable); ok {
+		return value.isFrozen()
+	}
+	return false
+}
+
+func lox_type(interpreter *Interpreter, arguments []Any) Any {
+	return typeName(arguments[0])
+}
+
+// fields returns sorted names of public fields of instance.
+func lox_fields(interpreter *Interpreter, arguments []Any) Any {
+	instance := instanceArgument(arguments, 0)
+	names := make([]string, 0, len(instance.fields))
+	for name := range instance.fields {
+		names = append(names, name)
+	}
+	return publicNames(names)
+}
+
+// methods returns sorted names of public methods declared by class, or
+// provided by its traits, inherited methods aren't included.
+func lox_methods(interpreter *Interpreter, arguments []Any) Any {
+	klass := classArgument(arguments, 0)
+	names := make([]string, 0, len(klass.methods))
+	for name := range klass.methods {
+		names = append(names, name)
+	}
+	return publicNames(names)
+}
+
+func lox_superclass(interpreter *Interpreter, arguments []Any) Any {
+	if superclass := classArgument(arguments, 0).superclass; superclass != nil {
+		return superclass
+	}
+	return nil
+}
+
+// nameOf returns name of function, class, trait, interface or enum, or nil
+// for anonymous and native functions.
+func lox_nameOf(interpreter *Interpreter, arguments []Any) Any {
+	switch value := arguments[0].(type) {
+	case *LoxFunction:
+		if value.declaration.name != nil {
+			return value.declaration.name.lexme
+		}
+		return nil
+	case *LoxStaticCallable:
+		return nil
+	case *LoxClass:
+		return value.name
+	case *LoxTrait:
+		return value.name
+	case *LoxInterface:
+		return value.name
+	case *LoxEnum:
+		return value.name
+	}
+	nativeError("Argument 1 must be a function, class, trait, interface or enum.")
+	return nil
+}
+
+// arity returns number of arguments accepted by function or class, or list
+// of minimum and maximum number when it has default or rest parameters. The
+// maximum is nil when the number isn't limited.
+func lox_arity(interpreter *Interpreter, arguments []Any) Any {
+	callable, ok := arguments[0].(LoxCallable)
+	if !ok {
+		nativeError("Argument 1 must be a function or a class.")
+	}
+
+	arity := callable.Arity()
+	if arity.min == arity.max {
+		return int64(arity.min)
+	}
+	if arity.max < 0 {
+		return MakeLoxList([]Any{int64(arity.min), nil})
+	}
+	return MakeLoxList([]Any{int64(arity.min), int64(arity.max)})
+}
+
+func lox_hasField(interpreter *Interpreter, arguments []Any) Any {
+	_, ok := instanceArgument(arguments, 0).fields[fieldArgument(arguments, 1)]
+	return ok
+}
+
+// getField returns value of field with given name, or nil when the instance
+// doesn't have it. Getters and methods aren't looked up.
+func lox_getField(interpreter *Interpreter, arguments []Any) Any {
+	return instanceArgument(arguments, 0).fields[fieldArgument(arguments, 1)]
+}
+
+// setField sets field with given name and returns the value, setters aren't
+// called.
+func lox_setField(interpreter *Interpreter, arguments []Any) Any {
+	instance := instanceArgument(arguments, 0)
+	name := fieldArgument(arguments, 1)
+	if instance.frozen {
+		nativeError("Can't modify frozen '%s' instance.", instance.klass.name)
+	}
+
+	instance.fields[name] = arguments[2]
+	return arguments[2]
+}
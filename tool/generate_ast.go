@@ -9,33 +9,51 @@ import (
 func main() {
 	types := []string{
 		// Expressions
-		"AssignExpr:   name *Token, value Expr",
+		"AssignExpr:   name *Token, operator *Token, value Expr",
 		"BinaryExpr:   left Expr, operator *Token, right Expr",
-		"CallExpr:     callee Expr, paren *Token, arguments []Expr",
-		"GetExpr:      object Expr, name *Token",
+		"CallExpr:     callee Expr, paren *Token, arguments []Expr, names []*Token",
+		"GetExpr:      object Expr, name *Token, optional bool",
 		"GroupingExpr: expression Expr",
 		"LiteralExpr:  value interface{}",
 		"LogicalExpr:  left Expr, operator *Token, right Expr",
-		"SetExpr:      object Expr, name *Token, value Expr",
+		"SetExpr:      object Expr, name *Token, operator *Token, value Expr",
 		"SuperExpr:    keyword *Token, method *Token",
 		"ThisExpr:     keyword *Token",
 		"UnaryExpr:    operator *Token, right Expr",
 		"VariableExpr: name *Token",
-		"FunctionExpr: name *Token, paren *Token, params []*Token, body []Stmt",
+		"FunctionExpr: name *Token, paren *Token, params []*Token, defaults []Expr, rest *Token, body []Stmt, doc string, generator bool",
+		"ListExpr:     bracket *Token, elements []Expr",
+		"MapExpr:      brace *Token, keys []Expr, values []Expr",
+		"IndexExpr:    object Expr, bracket *Token, index Expr",
+		"SetIndexExpr: object Expr, bracket *Token, index Expr, operator *Token, value Expr",
+		"UpdateExpr:   target Expr, operator *Token, prefix bool",
+		"ConditionalExpr: condition Expr, question *Token, thenBranch Expr, elseBranch Expr",
+		"RangeExpr:    start Expr, operator *Token, end Expr, step Expr",
+		"SliceExpr:    object Expr, bracket *Token, start Expr, end Expr, step Expr",
+		"SpreadExpr:   ellipsis *Token, expression Expr",
+		"DestructureExpr: pattern Expr, equals *Token, value Expr",
+		"InterpolationExpr: parts []Expr",
 
 		// Statements
 		"BlockStmt:      statements []Stmt",
-		"ClassStmt:      name *Token, superclass *VariableExpr, methods []*FunctionExpr",
+		"TraitStmt:      name *Token, methods []*FunctionExpr, doc string",
+		"InterfaceStmt:  name *Token, methods []*FunctionExpr, doc string",
+		"EnumStmt:       name *Token, members []*Token, params [][]*Token, docs []string, doc string",
+		"ClassStmt:      name *Token, superclass *VariableExpr, traits []*VariableExpr, interfaces []*VariableExpr, methods []*FunctionExpr, abstractMethods []*FunctionExpr, staticMethods []*FunctionExpr, getters []*FunctionExpr, setters []*FunctionExpr, fields []*VarStmt, staticFields []*VarStmt, doc string",
 		"ExpressionStmt: expression Expr",
 		"IfStmt:         condition Expr, thenBranch Stmt, elseBranch Stmt",
 		"PrintStmt:      expression Expr",
-		"VarStmt:        name *Token, initializer Expr",
+		"VarStmt:        name *Token, initializer Expr, doc string, constant bool",
+		"DestructureStmt: keyword *Token, pattern Expr, initializer Expr",
 		"WhileStmt:      condition Expr, body Stmt",
 		"ForStmt:        initializer Stmt, condition Expr, increment Expr, body Stmt",
+		"ForInStmt:      name *Token, keyword *Token, iterable Expr, body Stmt",
 		"ReturnStmt:     keyword *Token, value Expr",
+		"YieldStmt:      keyword *Token, value Expr",
 		"ContinueStmt:   keyword *Token",
 		"BreakStmt:      keyword *Token",
 		"IncludeStmt:    keyword *Token, path *Token",
+		"MatchStmt:      keyword *Token, subject Expr, cases []*Token, patterns [][]Expr, guards []Expr, bodies []Stmt",
 	}
 
 	defs := "package main\n\n"
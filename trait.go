@@ -0,0 +1,107 @@
+package main
+
+import "fmt"
+
+// LoxTrait is a named set of methods, which are copied into classes using
+// the trait. Methods declared by the class itself override them, and they
+// override methods inherited from the superclass.
+type LoxTrait struct {
+	name    string
+	methods []*LoxFunction
+}
+
+func (t *LoxTrait) String() string {
+	return fmt.Sprintf("trait %s", t.name)
+}
+
+func (i *Interpreter) visitTraitStmt(stmt *TraitStmt) Any {
+	trait := &LoxTrait{name: stmt.name.lexme, methods: make([]*LoxFunction, 0, len(stmt.methods))}
+	for _, method := range stmt.methods {
+		trait.methods = append(trait.methods, MakeLoxFunction(method, i.environment, false))
+	}
+
+	i.environment.declare(stmt.name, trait, false)
+	return nil
+}
+
+// traits evaluates traits used by class.
+func (i *Interpreter) traits(stmt *ClassStmt) []*LoxTrait {
+	traits := make([]*LoxTrait, 0, len(stmt.traits))
+	for _, expr := range stmt.traits {
+		trait, ok := i.evaluate(expr).(*LoxTrait)
+		if !ok {
+			i.context.runtimeError(expr.name, "'%s' isn't a trait.", expr.name.lexme)
+		}
+		traits = append(traits, trait)
+	}
+	return traits
+}
+
+// composeTraits adds methods of traits to methods declared by class. Traits
+// providing the same method conflict, unless the class overrides it.
+func (i *Interpreter) composeTraits(stmt *ClassStmt, traits []*LoxTrait, methods map[string]*LoxFunction) {
+	providers := make(map[string]*LoxTrait)
+
+	for index, trait := range traits {
+		for _, method := range trait.methods {
+			name := method.declaration.name.lexme
+			if provider, ok := providers[name]; ok {
+				i.context.runtimeError(stmt.traits[index].name, "Class '%s' gets method '%s' from traits '%s' and '%s', it must override it.", stmt.name.lexme, name, provider.name, trait.name)
+			}
+			if _, ok := methods[name]; ok {
+				continue
+			}
+
+			providers[name] = trait
+			methods[name] = MakeLoxFunction(method.declaration, method.closure, false)
+		}
+	}
+}
+
+// hasTrait reports whether the class, or any of its superclasses, uses
+// trait.
+func (c *LoxClass) hasTrait(trait *LoxTrait) bool {
+	for klass := c; klass != nil; klass = klass.superclass {
+		for _, used := range klass.traits {
+			if used == trait {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (r *Resolver) visitTraitStmt(stmt *TraitStmt) Any {
+	enclosingClass, enclosingStatic, enclosingPrivates := r.currentClass, r.currentStatic, r.currentPrivates
+	r.currentClass, r.currentStatic, r.currentPrivates = CLASS_TRAIT, false, make(map[string]bool)
+
+	r.declare(stmt.name)
+	r.define(stmt.name)
+
+	r.beginScope()
+	r.scopes.Peek()["this"] = true
+
+	for _, method := range stmt.methods {
+		if method.name == nil {
+			r.context.tokenError(method.paren, "Method must have a name.")
+		}
+		r.resolveFunction(method, FUNCTION_METHOD)
+	}
+
+	r.endScope()
+
+	r.currentClass, r.currentStatic, r.currentPrivates = enclosingClass, enclosingStatic, enclosingPrivates
+	return nil
+}
+
+// resolveTraits resolves names of traits used by class.
+func (r *Resolver) resolveTraits(stmt *ClassStmt) {
+	used := make(map[string]bool)
+	for _, trait := range stmt.traits {
+		if used[trait.name.lexme] {
+			r.context.tokenError(trait.name, "Trait '%s' is used more than once.", trait.name.lexme)
+		}
+		used[trait.name.lexme] = true
+		r.resolveExpr(trait)
+	}
+}
@@ -20,6 +20,17 @@ type ExprVisitor interface {
 	visitSuperExpr(expr *SuperExpr) Any
 	visitLogicalExpr(expr *LogicalExpr) Any
 	visitFunctionExpr(expr *FunctionExpr) Any
+	visitListExpr(expr *ListExpr) Any
+	visitMapExpr(expr *MapExpr) Any
+	visitIndexExpr(expr *IndexExpr) Any
+	visitSetIndexExpr(expr *SetIndexExpr) Any
+	visitUpdateExpr(expr *UpdateExpr) Any
+	visitConditionalExpr(expr *ConditionalExpr) Any
+	visitRangeExpr(expr *RangeExpr) Any
+	visitSliceExpr(expr *SliceExpr) Any
+	visitSpreadExpr(expr *SpreadExpr) Any
+	visitDestructureExpr(expr *DestructureExpr) Any
+	visitInterpolationExpr(expr *InterpolationExpr) Any
 }
 
 type Stmt interface {
@@ -35,8 +46,15 @@ type StmtVisitor interface {
 	visitWhileStmt(stmt *WhileStmt) Any
 	visitReturnStmt(stmt *ReturnStmt) Any
 	visitClassStmt(stmt *ClassStmt) Any
+	visitTraitStmt(stmt *TraitStmt) Any
+	visitInterfaceStmt(stmt *InterfaceStmt) Any
+	visitEnumStmt(stmt *EnumStmt) Any
 	visitForStmt(stmt *ForStmt) Any
 	visitContinueStmt(stmt *ContinueStmt) Any
 	visitBreakStmt(stmt *BreakStmt) Any
 	visitIncludeStmt(stmt *IncludeStmt) Any
+	visitMatchStmt(stmt *MatchStmt) Any
+	visitForInStmt(stmt *ForInStmt) Any
+	visitYieldStmt(stmt *YieldStmt) Any
+	visitDestructureStmt(stmt *DestructureStmt) Any
 }